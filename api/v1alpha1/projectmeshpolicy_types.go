@@ -0,0 +1,69 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProjectMeshPolicySpec defines how namespaces matching the selector are enrolled in the mesh.
+type ProjectMeshPolicySpec struct {
+	// NamespaceSelector selects namespaces the policy applies to. Empty selector matches all mesh-aware namespaces.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// ControlPlaneRef points to the ServiceMeshControlPlane selected namespaces become members of.
+	// When not set, control plane defined in the controller configuration is used.
+	// +optional
+	ControlPlaneRef *ControlPlaneReference `json:"controlPlaneRef,omitempty"`
+
+	// AllowedControlPlanes lists ServiceMeshControlPlanes selected namespaces can choose through the control plane
+	// annotation. Namespaces selecting any other control plane are enrolled in the default one.
+	// +optional
+	AllowedControlPlanes []ControlPlaneReference `json:"allowedControlPlanes,omitempty"`
+
+	// GatewayRouteSelector selects the Route exposing the Istio ingress gateway in the mesh namespace.
+	// When not set, selector defined in the controller configuration is used.
+	// +optional
+	GatewayRouteSelector *metav1.LabelSelector `json:"gatewayRouteSelector,omitempty"`
+
+	// Features lists names of the features enabled for selected namespaces. When empty, all features are enabled.
+	// +optional
+	Features []string `json:"features,omitempty"`
+
+	// Priority decides which policy is used when more than one matches the namespace. The highest one wins,
+	// ties are resolved by the name of the policy.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// ControlPlaneReference identifies the ServiceMeshControlPlane.
+type ControlPlaneReference struct {
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+}
+
+// ProjectMeshPolicy maps namespaces to the mesh settings they are enrolled with.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=pmp
+// +kubebuilder:printcolumn:name="Priority",type=integer,JSONPath=`.spec.priority`
+// +kubebuilder:printcolumn:name="Control Plane",type=string,JSONPath=`.spec.controlPlaneRef.name`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type ProjectMeshPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ProjectMeshPolicySpec `json:"spec,omitempty"`
+}
+
+// ProjectMeshPolicyList contains a list of ProjectMeshPolicy.
+// +kubebuilder:object:root=true
+type ProjectMeshPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProjectMeshPolicy `json:"items"`
+}
+
+func init() { //nolint:gochecknoinits //reason this is how types are registered in the scheme
+	SchemeBuilder.Register(&ProjectMeshPolicy{}, &ProjectMeshPolicyList{})
+}
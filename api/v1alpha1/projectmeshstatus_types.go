@@ -0,0 +1,104 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MeshPhase summarizes the state of the namespace enrolment in the mesh.
+// +kubebuilder:validation:Enum=Pending;Ready;Failed
+type MeshPhase string
+
+const (
+	// MeshPhasePending means the namespace is being enrolled, but either the control plane is not Ready
+	// or the mesh has not confirmed the membership yet.
+	MeshPhasePending MeshPhase = "Pending"
+	// MeshPhaseReady means all enabled features have been reconciled and the namespace is a member of the mesh.
+	MeshPhaseReady MeshPhase = "Ready"
+	// MeshPhaseFailed means at least one of the features failed to reconcile.
+	MeshPhaseFailed MeshPhase = "Failed"
+)
+
+// FeatureState is the outcome of the last reconciliation of the feature.
+// +kubebuilder:validation:Enum=Succeeded;Pending;Failed;Disabled;Unavailable;Blocked
+type FeatureState string
+
+const (
+	FeatureSucceeded FeatureState = "Succeeded"
+	FeaturePending   FeatureState = "Pending"
+	FeatureFailed    FeatureState = "Failed"
+	FeatureDisabled  FeatureState = "Disabled"
+	// FeatureUnavailable means the API the feature depends on is not served by the cluster.
+	FeatureUnavailable FeatureState = "Unavailable"
+	// FeatureBlocked means the feature has not been reconciled, because one of the features it depends on has not succeeded.
+	FeatureBlocked FeatureState = "Blocked"
+)
+
+// FeatureStatus reports the outcome of the last reconciliation of a single feature.
+type FeatureStatus struct {
+	Name  string       `json:"name"`
+	State FeatureState `json:"state"`
+	// Message explains why the feature failed or what it is waiting for.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ProjectMeshStatusStatus reports the state of the namespace enrolment in the mesh as observed by the controller.
+type ProjectMeshStatusStatus struct {
+	// +optional
+	Phase MeshPhase `json:"phase,omitempty"`
+
+	// Features lists the outcome of every feature run for the namespace.
+	// +optional
+	Features []FeatureStatus `json:"features,omitempty"`
+
+	// ControlPlane is the ServiceMeshControlPlane the namespace is a member of, in the <namespace>/<name> format.
+	// +optional
+	ControlPlane string `json:"controlPlane,omitempty"`
+
+	// MemberReady tells if the ServiceMeshMember has been reported Ready by the mesh.
+	// +optional
+	MemberReady bool `json:"memberReady,omitempty"`
+
+	// Gateway is the public gateway resolved for the namespace.
+	// +optional
+	Gateway string `json:"gateway,omitempty"`
+
+	// GatewayHost is the external host of the public gateway resolved for the namespace.
+	// +optional
+	GatewayHost string `json:"gatewayHost,omitempty"`
+
+	// LastError is the error of the last reconciliation, empty if it succeeded.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// ObservedNamespaceResourceVersion is the resourceVersion of the namespace the status has been computed for.
+	// +optional
+	ObservedNamespaceResourceVersion string `json:"observedNamespaceResourceVersion,omitempty"`
+}
+
+// ProjectMeshStatus reports the mesh state of the namespace it is created in.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=pms
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Member Ready",type=boolean,JSONPath=`.status.memberReady`
+// +kubebuilder:printcolumn:name="Gateway",type=string,JSONPath=`.status.gateway`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type ProjectMeshStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status ProjectMeshStatusStatus `json:"status,omitempty"`
+}
+
+// ProjectMeshStatusList contains a list of ProjectMeshStatus.
+// +kubebuilder:object:root=true
+type ProjectMeshStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProjectMeshStatus `json:"items"`
+}
+
+func init() { //nolint:gochecknoinits //reason this is how types are registered in the scheme
+	SchemeBuilder.Register(&ProjectMeshStatus{}, &ProjectMeshStatusList{})
+}
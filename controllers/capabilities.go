@@ -0,0 +1,276 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	maistrav1 "maistra.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Names of the optional APIs the built-in features depend on.
+const (
+	CapabilityServiceMesh = "service-mesh"
+	CapabilityRoutes      = "routes"
+)
+
+// Capability is an optional API features depend on, considered available when all its resources are served by the cluster.
+type Capability struct {
+	Name         string
+	GroupVersion schema.GroupVersion
+	Resources    []string
+}
+
+// ServiceMeshCapability stands for the OpenShift Service Mesh API.
+func ServiceMeshCapability() Capability {
+	return Capability{
+		Name:         CapabilityServiceMesh,
+		GroupVersion: maistrav1.SchemeGroupVersion,
+		Resources:    []string{"servicemeshmembers", "servicemeshcontrolplanes"},
+	}
+}
+
+// RoutesCapability stands for the OpenShift Route API.
+func RoutesCapability() Capability {
+	return Capability{
+		Name:         CapabilityRoutes,
+		GroupVersion: schema.GroupVersion{Group: "route.openshift.io", Version: "v1"},
+		Resources:    []string{"routes"},
+	}
+}
+
+func builtinCapabilities() []Capability {
+	return []Capability{ServiceMeshCapability(), RoutesCapability()}
+}
+
+// Capabilities tracks which of the optional APIs are served by the cluster. APIs which are not served
+// at startup can be discovered later, e.g. when OpenShift Service Mesh is installed after the controller.
+type Capabilities struct {
+	discovery    discovery.DiscoveryInterface
+	mu           sync.RWMutex
+	tracked      map[string]Capability
+	available    map[string]bool
+	allAvailable bool
+}
+
+// NewCapabilities creates Capabilities tracking built-in APIs, with none of them available until discovered.
+func NewCapabilities(discovery discovery.DiscoveryInterface) *Capabilities {
+	capabilities := &Capabilities{
+		discovery: discovery,
+		tracked:   map[string]Capability{},
+		available: map[string]bool{},
+	}
+	capabilities.Track(builtinCapabilities()...)
+
+	return capabilities
+}
+
+// AllCapabilities creates Capabilities assuming all APIs are served by the cluster.
+func AllCapabilities() *Capabilities {
+	capabilities := NewCapabilities(nil)
+	capabilities.allAvailable = true
+
+	return capabilities
+}
+
+// DiscoverCapabilities creates Capabilities with APIs currently served by the cluster marked as available.
+// Capabilities are returned even if discovery fails, with APIs which could not be discovered treated as missing.
+func DiscoverCapabilities(discovery discovery.DiscoveryInterface) (*Capabilities, error) {
+	capabilities := NewCapabilities(discovery)
+
+	discovered, err := capabilities.Discover()
+	for _, name := range discovered {
+		capabilities.MarkAvailable(name)
+	}
+
+	return capabilities, err
+}
+
+// Track adds capabilities to be discovered. Capabilities already tracked are left intact.
+func (c *Capabilities) Track(capabilities ...Capability) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, capability := range capabilities {
+		if _, tracked := c.tracked[capability.Name]; !tracked {
+			c.tracked[capability.Name] = capability
+		}
+	}
+}
+
+// Has checks if all given capabilities are available.
+func (c *Capabilities) Has(names ...string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.allAvailable {
+		return true
+	}
+
+	for _, name := range names {
+		if !c.available[name] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Missing lists tracked capabilities which are not available.
+func (c *Capabilities) Missing() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var missing []string
+
+	if c.allAvailable {
+		return missing
+	}
+
+	for name := range c.tracked {
+		if !c.available[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	sort.Strings(missing)
+
+	return missing
+}
+
+// Discover returns missing capabilities whose APIs are now served by the cluster.
+// They have to be marked as available with MarkAvailable once features depending on them are ready to use them.
+func (c *Capabilities) Discover() ([]string, error) {
+	var discovered []string
+
+	var errs []error
+
+	for _, name := range c.Missing() {
+		served, err := isServed(c.discovery, c.capability(name))
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		if served {
+			discovered = append(discovered, name)
+		}
+	}
+
+	if len(errs) > 0 {
+		return discovered, errors.Wrap(errs[0], "unable to discover capabilities")
+	}
+
+	return discovered, nil
+}
+
+// MarkAvailable enables features depending on the capability.
+func (c *Capabilities) MarkAvailable(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.available[name] = true
+}
+
+func (c *Capabilities) capability(name string) Capability {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.tracked[name]
+}
+
+func isServed(discovery discovery.DiscoveryInterface, capability Capability) (bool, error) {
+	served, err := discovery.ServerResourcesForGroupVersion(capability.GroupVersion.String())
+	if err != nil {
+		if apierrs.IsNotFound(err) {
+			return false, nil
+		}
+
+		return false, errors.Wrapf(err, "unable to discover %s API", capability.GroupVersion)
+	}
+
+	for _, resource := range capability.Resources {
+		if !containsResource(served.APIResources, resource) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// capabilityDiscoveryInterval is how often missing APIs are looked up.
+const capabilityDiscoveryInterval = time.Minute
+
+// discoverCapabilities periodically looks up missing APIs until all of them are served.
+func (r *OpenshiftServiceMeshReconciler) discoverCapabilities(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if len(r.Capabilities.Missing()) > 0 {
+			r.enableDiscoveredCapabilities(ctx)
+		}
+	}, capabilityDiscoveryInterval)
+
+	return nil
+}
+
+// enableDiscoveredCapabilities starts watches of features which requirements are now met and requeues
+// mesh-aware namespaces, so that the features get reconciled.
+func (r *OpenshiftServiceMeshReconciler) enableDiscoveredCapabilities(ctx context.Context) {
+	discovered, err := r.Capabilities.Discover()
+	if err != nil {
+		r.Log.Error(err, "Unable to discover missing APIs")
+	}
+
+	if len(discovered) == 0 {
+		return
+	}
+
+	for _, capability := range discovered {
+		r.Capabilities.MarkAvailable(capability)
+		r.Log.Info("Discovered API, enabling features depending on it", "capability", capability)
+	}
+
+	for _, f := range r.Features.Features() {
+		if r.watching[f.Name] || !r.Capabilities.Has(f.requiredCapabilities()...) {
+			continue
+		}
+
+		if err := r.startFeatureWatches(f); err != nil {
+			r.Log.Error(err, "Unable to watch resources of the feature", "feature", f.Name)
+
+			continue
+		}
+	}
+
+	for _, request := range r.meshAwareNamespaceRequests(ctx) {
+		namespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: request.Name}}
+
+		select {
+		case r.requeue <- event.GenericEvent{Object: namespace}:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (r *OpenshiftServiceMeshReconciler) startFeatureWatches(f Feature) error {
+	for _, w := range f.Watches {
+		if err := r.controller.Watch(source.Kind(r.cache, w.Object), w.Handler); err != nil {
+			return errors.Wrapf(err, "failed watching %T", w.Object)
+		}
+	}
+
+	r.watching[f.Name] = true
+
+	return nil
+}
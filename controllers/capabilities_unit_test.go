@@ -0,0 +1,109 @@
+package controllers_test
+
+import (
+	"github.com/opendatahub-io/odh-project-controller/controllers"
+	"github.com/opendatahub-io/odh-project-controller/test/labels"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Capabilities", Label(labels.Unit), func() {
+
+	var discovery *fakediscovery.FakeDiscovery
+
+	routeAPI := &metav1.APIResourceList{
+		GroupVersion: "route.openshift.io/v1",
+		APIResources: []metav1.APIResource{{Name: "routes"}},
+	}
+
+	maistraAPI := &metav1.APIResourceList{
+		GroupVersion: "maistra.io/v1",
+		APIResources: []metav1.APIResource{{Name: "servicemeshmembers"}, {Name: "servicemeshcontrolplanes"}},
+	}
+
+	BeforeEach(func() {
+		discovery = &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+	})
+
+	It("should report APIs not served by the cluster as missing", func() {
+		// given
+		discovery.Resources = []*metav1.APIResourceList{routeAPI}
+
+		// when
+		capabilities, err := controllers.DiscoverCapabilities(discovery)
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(capabilities.Has(controllers.CapabilityRoutes)).To(BeTrue())
+		Expect(capabilities.Has(controllers.CapabilityServiceMesh)).To(BeFalse())
+		Expect(capabilities.Missing()).To(ConsistOf(controllers.CapabilityServiceMesh))
+	})
+
+	It("should treat partially served API as missing", func() {
+		// given
+		discovery.Resources = []*metav1.APIResourceList{routeAPI, {
+			GroupVersion: "maistra.io/v1",
+			APIResources: []metav1.APIResource{{Name: "servicemeshmembers"}},
+		}}
+
+		// when
+		capabilities, err := controllers.DiscoverCapabilities(discovery)
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(capabilities.Has(controllers.CapabilityServiceMesh)).To(BeFalse())
+	})
+
+	It("should keep capabilities discovered before failure", func() {
+		// given
+		discovery.Resources = []*metav1.APIResourceList{routeAPI, maistraAPI}
+		failing := &failingDiscovery{FakeDiscovery: discovery, failing: "maistra.io/v1"}
+
+		// when
+		capabilities, err := controllers.DiscoverCapabilities(failing)
+
+		// then
+		Expect(err).To(HaveOccurred())
+		Expect(capabilities.Has(controllers.CapabilityRoutes)).To(BeTrue())
+		Expect(capabilities.Missing()).To(ConsistOf(controllers.CapabilityServiceMesh))
+	})
+
+	It("should discover API installed later without enabling it", func() {
+		// given
+		discovery.Resources = []*metav1.APIResourceList{routeAPI}
+		capabilities, err := controllers.DiscoverCapabilities(discovery)
+		Expect(err).ToNot(HaveOccurred())
+
+		// when
+		discovery.Resources = append(discovery.Resources, maistraAPI)
+		discovered, err := capabilities.Discover()
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(discovered).To(ConsistOf(controllers.CapabilityServiceMesh))
+		Expect(capabilities.Has(controllers.CapabilityServiceMesh)).To(BeFalse())
+
+		capabilities.MarkAvailable(controllers.CapabilityServiceMesh)
+		Expect(capabilities.Missing()).To(BeEmpty())
+	})
+
+})
+
+// failingDiscovery fails to look up resources of the given group version, e.g. when its aggregated API is unavailable.
+type failingDiscovery struct {
+	*fakediscovery.FakeDiscovery
+	failing string
+}
+
+func (f *failingDiscovery) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	if groupVersion == f.failing {
+		return nil, apierrs.NewServiceUnavailable("discovery failed")
+	}
+
+	return f.FakeDiscovery.ServerResourcesForGroupVersion(groupVersion)
+}
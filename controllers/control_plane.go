@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	maistrav1 "maistra.io/api/core/v1"
+)
+
+// ControlPlaneNotReadyError is returned when the ServiceMeshControlPlane the namespace should be a member of
+// is missing or not Ready yet. Enrolment is then held until the control plane becomes Ready.
+type ControlPlaneNotReadyError struct {
+	ControlPlane maistrav1.ServiceMeshControlPlaneRef
+	Reason       string
+}
+
+func (e *ControlPlaneNotReadyError) Error() string {
+	return fmt.Sprintf("waiting for ServiceMeshControlPlane %s/%s: %s", e.ControlPlane.Namespace, e.ControlPlane.Name, e.Reason)
+}
+
+// isPending checks if the error means the feature is waiting for its dependencies rather than failing.
+func isPending(err error) bool {
+	var notReady *ControlPlaneNotReadyError
+
+	return errors.As(err, &notReady)
+}
+
+// ControlPlaneNotAllowedError is returned when the namespace selects, through the annotation, a ServiceMeshControlPlane
+// not allowed by the matching ProjectMeshPolicy. The namespace is then enrolled in the default control plane.
+type ControlPlaneNotAllowedError struct {
+	ControlPlane maistrav1.ServiceMeshControlPlaneRef
+}
+
+func (e *ControlPlaneNotAllowedError) Error() string {
+	return fmt.Sprintf("ServiceMeshControlPlane %s/%s is not allowed by ProjectMeshPolicy, using the default one",
+		e.ControlPlane.Namespace, e.ControlPlane.Name)
+}
+
+func isControlPlaneNotAllowed(err error) bool {
+	var notAllowed *ControlPlaneNotAllowedError
+
+	return errors.As(err, &notAllowed)
+}
+
+func (r *OpenshiftServiceMeshReconciler) checkControlPlaneReady(ctx context.Context, controlPlane maistrav1.ServiceMeshControlPlaneRef) error {
+	smcp := &maistrav1.ServiceMeshControlPlane{}
+	if err := r.Get(ctx, types.NamespacedName{Name: controlPlane.Name, Namespace: controlPlane.Namespace}, smcp); err != nil {
+		if apierrs.IsNotFound(err) {
+			return &ControlPlaneNotReadyError{ControlPlane: controlPlane, Reason: "it does not exist"}
+		}
+
+		return errors.Wrapf(err, "unable to fetch ServiceMeshControlPlane %s/%s", controlPlane.Namespace, controlPlane.Name)
+	}
+
+	if !isControlPlaneReady(smcp) {
+		return &ControlPlaneNotReadyError{ControlPlane: controlPlane, Reason: "it is not Ready"}
+	}
+
+	return nil
+}
+
+func isControlPlaneReady(smcp *maistrav1.ServiceMeshControlPlane) bool {
+	for _, condition := range smcp.Status.Conditions {
+		if string(condition.Type) == string(maistrav1.ConditionTypeReady) {
+			return string(condition.Status) == string(v1.ConditionTrue)
+		}
+	}
+
+	return false
+}
@@ -11,6 +11,7 @@ import (
 	"go.uber.org/zap/zapcore"
 	v1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	maistramanifests "maistra.io/api/manifests"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -33,6 +34,8 @@ var (
 
 var testScheme = runtime.NewScheme()
 
+var meshConfigSource = types.NamespacedName{Namespace: "istio-system", Name: controllers.MeshConfigMapName}
+
 func TestController(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Controller & Webhook Suite")
@@ -50,12 +53,15 @@ var _ = SynchronizedBeforeSuite(func() {
 	}
 	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseFlagOptions(&opts)))
 
+	controllers.RegisterSchemes(testScheme)
+	utilruntime.Must(v1.AddToScheme(testScheme))
+
 	By("Bootstrapping k8s test environment")
 	envTest = &envtest.Environment{
 		CRDInstallOptions: envtest.CRDInstallOptions{
 			Scheme:             testScheme,
 			CRDs:               loadCRDs(),
-			Paths:              []string{filepath.Join("..", "config", "crd", "external")},
+			Paths:              []string{filepath.Join("..", "config", "crd", "external"), filepath.Join("..", "config", "crd", "bases")},
 			ErrorIfPathMissing: true,
 			CleanUpAfterUse:    false,
 		},
@@ -65,9 +71,6 @@ var _ = SynchronizedBeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(cfg).NotTo(BeNil())
 
-	controllers.RegisterSchemes(testScheme)
-	utilruntime.Must(v1.AddToScheme(testScheme))
-
 	cli, err = client.New(cfg, client.Options{Scheme: testScheme})
 	Expect(err).NotTo(HaveOccurred())
 	Expect(cli).NotTo(BeNil())
@@ -83,6 +86,7 @@ var _ = SynchronizedBeforeSuite(func() {
 		Client: cli,
 		Log:    ctrl.Log.WithName("controllers").WithName("project-controller"),
 		Scheme: mgr.GetScheme(),
+		Config: controllers.NewMeshConfigStore(controllers.NewMeshConfigFromEnv(), meshConfigSource),
 	}).SetupWithManager(mgr)
 	Expect(err).ToNot(HaveOccurred())
 
@@ -102,13 +106,19 @@ var _ = SynchronizedAfterSuite(func() {}, func() {
 })
 
 func loadCRDs() []*v1.CustomResourceDefinition {
-	smmYaml, err := maistramanifests.ReadManifest("maistra.io_servicemeshmembers.yaml")
-	Expect(err).NotTo(HaveOccurred())
+	var crds []*v1.CustomResourceDefinition
 
-	crd := &v1.CustomResourceDefinition{}
+	for _, manifest := range []string{"maistra.io_servicemeshmembers.yaml", "maistra.io_servicemeshcontrolplanes.yaml"} {
+		crdYaml, err := maistramanifests.ReadManifest(manifest)
+		Expect(err).NotTo(HaveOccurred())
 
-	err = controllers.ConvertToStructuredResource(smmYaml, crd)
-	Expect(err).NotTo(HaveOccurred())
+		manifest, err := controllers.LoadManifest(testScheme, crdYaml)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, crd := range manifest.Filter(controllers.ByGVK(v1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))) {
+			crds = append(crds, crd.(*v1.CustomResourceDefinition))
+		}
+	}
 
-	return []*v1.CustomResourceDefinition{crd}
+	return crds
 }
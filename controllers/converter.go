@@ -5,23 +5,171 @@ import (
 
 	"github.com/manifestival/manifestival"
 	"github.com/pkg/errors"
-	"k8s.io/client-go/kubernetes/scheme"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8serrs "k8s.io/apimachinery/pkg/util/errors"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func ConvertToStructuredResource(yamlContent []byte, out interface{}, opts ...manifestival.Option) error {
-	reader := bytes.NewReader(yamlContent)
+// Manifest holds typed objects read from a multi-document YAML stream.
+type Manifest struct {
+	scheme    *runtime.Scheme
+	resources []unstructured.Unstructured
+	objects   []client.Object
+}
+
+// ObjectPredicate selects objects of the manifest.
+type ObjectPredicate func(object client.Object, gvk schema.GroupVersionKind) bool
+
+// ByGVK selects objects of the given group, version and kind.
+func ByGVK(gvk schema.GroupVersionKind) ObjectPredicate {
+	return func(_ client.Object, objectGvk schema.GroupVersionKind) bool {
+		return objectGvk == gvk
+	}
+}
+
+// ByName selects objects of the given name.
+func ByName(name string) ObjectPredicate {
+	return func(object client.Object, _ schema.GroupVersionKind) bool {
+		return object.GetName() == name
+	}
+}
+
+// LoadManifest reads all objects from the YAML stream and converts them to the types registered in the scheme.
+// Fails when the stream has no objects or any of them is of a kind unknown to the scheme.
+func LoadManifest(scheme *runtime.Scheme, yamlContent []byte, opts ...manifestival.Option) (*Manifest, error) {
+	source, err := manifestival.ManifestFrom(manifestival.Reader(bytes.NewReader(yamlContent)), opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed reading manifest")
+	}
+
+	resources := source.Resources()
+	if len(resources) == 0 {
+		return nil, errors.New("manifest has no objects")
+	}
+
+	manifest := &Manifest{scheme: scheme, resources: resources}
+
+	var errs []error
+
+	for i := range resources {
+		gvk := resources[i].GroupVersionKind()
+		if !scheme.Recognizes(gvk) {
+			errs = append(errs, errors.Errorf("unknown kind %s of object %q", gvk, resources[i].GetName()))
+
+			continue
+		}
+
+		object, err := scheme.New(gvk)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed creating %s", gvk))
+
+			continue
+		}
+
+		if err := scheme.Convert(&resources[i], object, nil); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed converting %s %q", gvk.Kind, resources[i].GetName()))
+
+			continue
+		}
+
+		typed, ok := object.(client.Object)
+		if !ok {
+			errs = append(errs, errors.Errorf("%s is not a Kubernetes object", gvk))
+
+			continue
+		}
+
+		manifest.objects = append(manifest.objects, typed)
+	}
+
+	if err := k8serrs.NewAggregate(errs); err != nil {
+		return nil, errors.Wrap(err, "failed loading manifest")
+	}
 
-	manifest, err := manifestival.ManifestFrom(manifestival.Reader(reader), opts...)
+	return manifest, nil
+}
+
+// Objects returns all objects of the manifest, in the order of their documents.
+func (m *Manifest) Objects() []client.Object {
+	objects := make([]client.Object, len(m.objects))
+	copy(objects, m.objects)
+
+	return objects
+}
+
+// Filter returns objects matching all the predicates.
+func (m *Manifest) Filter(predicates ...ObjectPredicate) []client.Object {
+	var matching []client.Object
+
+	for i, object := range m.objects {
+		if matchesAll(object, m.resources[i].GroupVersionKind(), predicates) {
+			matching = append(matching, object)
+		}
+	}
+
+	return matching
+}
+
+// Into reads the only object of the out's kind matching all the predicates. Fails when none or more than one is found.
+func (m *Manifest) Into(out client.Object, predicates ...ObjectPredicate) error {
+	gvks, _, err := m.scheme.ObjectKinds(out)
 	if err != nil {
-		return errors.Wrap(err, "failed reading manifest")
+		return errors.Wrapf(err, "unknown kind of %T", out)
 	}
 
-	s := scheme.Scheme
+	predicates = append([]ObjectPredicate{ByGVK(gvks[0])}, predicates...)
+
+	found := -1
+
+	for i, object := range m.objects {
+		if !matchesAll(object, m.resources[i].GroupVersionKind(), predicates) {
+			continue
+		}
+
+		if found >= 0 {
+			return errors.Errorf("more than one %s found in manifest", gvks[0].Kind)
+		}
+
+		found = i
+	}
+
+	if found < 0 {
+		return errors.Errorf("no %s found in manifest", gvks[0].Kind)
+	}
+
+	return errors.Wrap(m.scheme.Convert(&m.resources[found], out, nil), "failed converting manifest")
+}
+
+func matchesAll(object client.Object, gvk schema.GroupVersionKind, predicates []ObjectPredicate) bool {
+	for _, predicate := range predicates {
+		if !predicate(object, gvk) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ConvertToStructuredResource reads the single object of the YAML content into out.
+// Besides the resources used by the controller, it understands CustomResourceDefinitions.
+// Use LoadManifest for multi-document content.
+func ConvertToStructuredResource(yamlContent []byte, out client.Object, opts ...manifestival.Option) error {
+	s := runtime.NewScheme()
 	RegisterSchemes(s)
+	utilruntime.Must(apiextensionsv1.AddToScheme(s))
+
+	manifest, err := LoadManifest(s, yamlContent, opts...)
+	if err != nil {
+		return err
+	}
 
-	if err := s.Convert(&manifest.Resources()[0], out, nil); err != nil {
-		return errors.Wrap(err, "failed converting manifest")
+	if len(manifest.objects) > 1 {
+		return errors.Errorf("expected single object, manifest has %d", len(manifest.objects))
 	}
 
-	return nil
+	return manifest.Into(out)
 }
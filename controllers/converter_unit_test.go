@@ -0,0 +1,126 @@
+package controllers_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/opendatahub-io/odh-project-controller/controllers"
+	"github.com/opendatahub-io/odh-project-controller/test/labels"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Loading manifests", Label(labels.Unit), func() {
+
+	const namespaces = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: first-ns
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: second-ns
+---
+apiVersion: route.openshift.io/v1
+kind: Route
+metadata:
+  name: odh-dashboard
+  namespace: istio-system
+`
+
+	var scheme *runtime.Scheme
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		controllers.RegisterSchemes(scheme)
+	})
+
+	It("should read every object of multi-document stream", func() {
+		// when
+		manifest, err := controllers.LoadManifest(scheme, []byte(namespaces))
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(manifest.Objects()).To(HaveLen(3))
+		Expect(manifest.Objects()[0]).To(BeAssignableToTypeOf(&corev1.Namespace{}))
+		Expect(manifest.Objects()[2]).To(BeAssignableToTypeOf(&routev1.Route{}))
+	})
+
+	It("should select objects by kind and name", func() {
+		// given
+		manifest, err := controllers.LoadManifest(scheme, []byte(namespaces))
+		Expect(err).ToNot(HaveOccurred())
+
+		// when
+		selected := manifest.Filter(controllers.ByGVK(corev1.SchemeGroupVersion.WithKind("Namespace")))
+
+		namespace := &corev1.Namespace{}
+		err = manifest.Into(namespace, controllers.ByName("second-ns"))
+
+		// then
+		Expect(selected).To(HaveLen(2))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(namespace.Name).To(Equal("second-ns"))
+	})
+
+	It("should fail reading single object when more than one matches", func() {
+		// given
+		manifest, err := controllers.LoadManifest(scheme, []byte(namespaces))
+		Expect(err).ToNot(HaveOccurred())
+
+		// when
+		err = manifest.Into(&corev1.Namespace{})
+
+		// then
+		Expect(err).To(MatchError(ContainSubstring("more than one Namespace")))
+	})
+
+	DescribeTable("it should reject invalid content",
+		func(content, expectedErr string) {
+			_, err := controllers.LoadManifest(scheme, []byte(content))
+			Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("for empty input", "", "manifest has no objects"),
+		Entry("for unknown kind", `
+apiVersion: example.com/v1
+kind: Unknown
+metadata:
+  name: unknown
+`, `unknown kind example.com/v1, Kind=Unknown of object "unknown"`),
+	)
+
+	It("should refuse converting multi-document stream into single object", func() {
+		// given
+		namespace := &corev1.Namespace{}
+
+		// when
+		err := controllers.ConvertToStructuredResource([]byte(namespaces), namespace)
+
+		// then
+		Expect(err).To(MatchError(ContainSubstring("expected single object")))
+	})
+
+	It("should convert CustomResourceDefinition", func() {
+		// given
+		crdYaml, err := os.ReadFile(filepath.Join("..", "config", "crd", "bases", "service-mesh.opendatahub.io_projectmeshpolicies.yaml"))
+		Expect(err).ToNot(HaveOccurred())
+
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+
+		// when
+		err = controllers.ConvertToStructuredResource(crdYaml, crd)
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(crd.Name).To(Equal("projectmeshpolicies.service-mesh.opendatahub.io"))
+		Expect(crd.Spec.Names.Kind).To(Equal("ProjectMeshPolicy"))
+	})
+
+})
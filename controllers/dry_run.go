@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	maistrav1 "maistra.io/api/core/v1"
+)
+
+// Change describes a single field which the controller would change when not running in dry-run mode.
+type Change struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %q -> %q", c.Field, c.From, c.To)
+}
+
+// reportDryRun logs, counts and emits an event for changes which have not been applied because of the dry-run mode.
+func (r *OpenshiftServiceMeshReconciler) reportDryRun(namespace *v1.Namespace, feature, operation, action string, changes []Change) {
+	r.Log.Info("Dry run, skipping changes", "namespace", namespace.Name, "feature", feature, "operation", operation, "changes", changes)
+
+	dryRunChanges.WithLabelValues(feature, operation).Inc()
+	if len(changes) == 0 {
+		r.Recorder.Eventf(namespace, v1.EventTypeNormal, ReasonDryRun, "Would %s", action)
+
+		return
+	}
+
+	r.Recorder.Eventf(namespace, v1.EventTypeNormal, ReasonDryRun, "Would %s: %s", action, formatChanges(changes))
+}
+
+func formatChanges(changes []Change) string {
+	descriptions := make([]string, 0, len(changes))
+	for _, change := range changes {
+		descriptions = append(descriptions, change.String())
+	}
+
+	return strings.Join(descriptions, ", ")
+}
+
+// meshMemberChanges compares ServiceMeshMember fields managed by the controller. Nil found member stands for the one to be created.
+func meshMemberChanges(found, desired *maistrav1.ServiceMeshMember) []Change {
+	current := &maistrav1.ServiceMeshMember{}
+	if found != nil {
+		current = found
+	}
+
+	var changes []Change
+
+	if from, to := controlPlaneOfMember(current), controlPlaneOfMember(desired); from != to {
+		changes = append(changes, Change{Field: "spec.controlPlaneRef", From: from, To: to})
+	}
+
+	changes = append(changes, mapChanges("metadata.labels", current.Labels, desired.Labels)...)
+
+	if from, to := ownersOf(current), ownersOf(desired); from != to {
+		changes = append(changes, Change{Field: "metadata.ownerReferences", From: from, To: to})
+	}
+
+	return changes
+}
+
+// mapChanges compares values of all keys of the given maps, e.g. namespace annotations.
+func mapChanges(field string, before, after map[string]string) []Change {
+	keys := map[string]bool{}
+	for key := range before {
+		keys[key] = true
+	}
+
+	for key := range after {
+		keys[key] = true
+	}
+
+	var changes []Change
+
+	for key := range keys {
+		if before[key] != after[key] {
+			changes = append(changes, Change{Field: field + "[" + key + "]", From: before[key], To: after[key]})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes
+}
+
+func controlPlaneOfMember(member *maistrav1.ServiceMeshMember) string {
+	ref := member.Spec.ControlPlaneRef
+	if ref.Name == "" {
+		return ""
+	}
+
+	return ref.Namespace + "/" + ref.Name
+}
+
+func ownersOf(member *maistrav1.ServiceMeshMember) string {
+	owners := make([]string, 0, len(member.OwnerReferences))
+	for _, owner := range member.OwnerReferences {
+		owners = append(owners, owner.Kind+"/"+owner.Name)
+	}
+
+	return strings.Join(owners, ",")
+}
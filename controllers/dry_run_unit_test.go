@@ -0,0 +1,153 @@
+package controllers_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/opendatahub-io/odh-project-controller/controllers"
+	"github.com/opendatahub-io/odh-project-controller/test/labels"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	maistrav1 "maistra.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dry-run mode", Label(labels.Unit), func() {
+
+	It("should report changes without applying them", func() {
+		// given
+		scheme := runtime.NewScheme()
+		controllers.RegisterSchemes(scheme)
+
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "dry-run-ns",
+				Annotations: map[string]string{controllers.AnnotationServiceMesh: "true"},
+			},
+		}
+		controlPlane := &maistrav1.ServiceMeshControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: "basic", Namespace: "istio-system"},
+		}
+		controlPlane.Status.Conditions = []maistrav1.Condition{
+			{Type: maistrav1.ConditionTypeReady, Status: maistrav1.ConditionStatusTrue},
+		}
+		route := &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "odh-dashboard",
+				Namespace: "istio-system",
+				Labels:    map[string]string{"app": "odh-dashboard"},
+			},
+			Spec: routev1.RouteSpec{
+				Host: "istio.io",
+				To:   routev1.RouteTargetReference{Name: "istio-ingressgateway"},
+			},
+		}
+
+		cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace, controlPlane, route).Build()
+		recorder := record.NewFakeRecorder(10)
+
+		features := controllers.NewFeatureRegistry()
+		reconciler := &controllers.OpenshiftServiceMeshReconciler{
+			Client:       cli,
+			Scheme:       scheme,
+			Log:          logr.Discard(),
+			Config:       controllers.NewMeshConfigStore(controllers.NewMeshConfigFromEnv(), meshConfigSource),
+			Reserved:     controllers.DefaultReservedNamespaces(),
+			Recorder:     recorder,
+			Capabilities: controllers.AllCapabilities(),
+			Features:     features,
+			DryRun:       true,
+		}
+		Expect(features.Register(reconciler.BuiltinFeatures()...)).To(Succeed())
+
+		// when
+		_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: namespace.Name}})
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+
+		member := &maistrav1.ServiceMeshMember{}
+		err = cli.Get(context.Background(), types.NamespacedName{Namespace: namespace.Name, Name: "default"}, member)
+		Expect(apierrs.IsNotFound(err)).To(BeTrue())
+
+		reconciled := &corev1.Namespace{}
+		Expect(cli.Get(context.Background(), client.ObjectKeyFromObject(namespace), reconciled)).To(Succeed())
+		Expect(reconciled.Finalizers).To(BeEmpty())
+		Expect(reconciled.Annotations).ToNot(HaveKey(controllers.AnnotationPublicGatewayExternalHost))
+
+		Expect(recorder.Events).To(HaveLen(2))
+		Expect(<-recorder.Events).To(And(
+			ContainSubstring(controllers.ReasonDryRun),
+			ContainSubstring(`metadata.annotations[`+controllers.AnnotationPublicGatewayExternalHost+`]: "" -> "istio.io"`),
+		))
+		Expect(<-recorder.Events).To(And(
+			ContainSubstring(controllers.ReasonDryRun),
+			ContainSubstring(`spec.controlPlaneRef: "" -> "istio-system/basic"`),
+		))
+	})
+
+	It("should keep record of templated resources when tearing down namespace", func() {
+		// given
+		scheme := runtime.NewScheme()
+		controllers.RegisterSchemes(scheme)
+
+		templated := `[{"apiVersion":"v1","kind":"ConfigMap","name":"templated"}]`
+		now := metav1.Now()
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "dry-run-deleted-ns",
+				DeletionTimestamp: &now,
+				Finalizers:        []string{controllers.FinalizerServiceMesh},
+				Annotations: map[string]string{
+					controllers.AnnotationServiceMesh:       "true",
+					controllers.AnnotationTemplateResources: templated,
+				},
+			},
+		}
+
+		var updatedRecords []string
+
+		cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace).WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, cli client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				if _, isNamespace := obj.(*corev1.Namespace); isNamespace {
+					updatedRecords = append(updatedRecords, obj.GetAnnotations()[controllers.AnnotationTemplateResources])
+				}
+
+				return cli.Update(ctx, obj, opts...)
+			},
+		}).Build()
+
+		features := controllers.NewFeatureRegistry()
+		reconciler := &controllers.OpenshiftServiceMeshReconciler{
+			Client:       cli,
+			Scheme:       scheme,
+			Log:          logr.Discard(),
+			Config:       controllers.NewMeshConfigStore(controllers.NewMeshConfigFromEnv(), meshConfigSource),
+			Reserved:     controllers.DefaultReservedNamespaces(),
+			Recorder:     record.NewFakeRecorder(10),
+			Capabilities: controllers.AllCapabilities(),
+			Features:     features,
+			DryRun:       true,
+		}
+		Expect(features.Register(reconciler.BuiltinFeatures()...)).To(Succeed())
+
+		// when
+		_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: namespace.Name}})
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(updatedRecords).To(HaveEach(templated))
+	})
+
+})
@@ -4,34 +4,71 @@ import (
 	"context"
 	"reflect"
 	"strconv"
+	"strings"
 
-	routev1 "github.com/openshift/api/route/v1"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/util/retry"
 	maistrav1 "maistra.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// serviceMeshMemberName is the name of the ServiceMeshMember created in the namespace.
+// The name MUST be default, per the maistra docs.
+const serviceMeshMemberName = "default"
+
 // Reconcile will manage the creation, update and deletion of the MeshMember for created the namespace.
 func (r *OpenshiftServiceMeshReconciler) reconcileMeshMember(ctx context.Context, namespace *v1.Namespace) error {
 	log := r.Log.WithValues("feature", "mesh", "namespace", namespace.Name)
 
-	desiredMeshMember := newServiceMeshMember(namespace)
+	config, err := r.meshConfigFor(ctx, namespace)
+	if err != nil {
+		log.Error(err, "Unable to resolve mesh configuration")
+
+		return err
+	}
+
+	controlPlane, err := r.controlPlaneFor(ctx, namespace, config)
+	if isControlPlaneNotAllowed(err) {
+		log.Info("Ignoring control plane selected by the namespace", "reason", err.Error())
+		r.Recorder.Event(namespace, v1.EventTypeWarning, ReasonControlPlaneNotAllowed, err.Error())
+	} else if err != nil {
+		log.Error(err, "Unable to determine control plane")
+
+		return err
+	}
+
+	// Enrolment is held until the control plane is able to accept members
+	if err := r.checkControlPlaneReady(ctx, controlPlane); err != nil {
+		if isPending(err) {
+			log.Info("Holding enrolment", "reason", err.Error())
+		} else {
+			log.Error(err, "Unable to use selected control plane")
+		}
+
+		return err
+	}
+
+	desiredMeshMember := newServiceMeshMember(namespace, controlPlane)
 	foundMember := &maistrav1.ServiceMeshMember{}
 	justCreated := false
 
-	err := r.Get(ctx, types.NamespacedName{
+	err = r.Get(ctx, types.NamespacedName{
 		Name:      desiredMeshMember.Name,
 		Namespace: namespace.Name,
 	}, foundMember)
 	if err != nil {
 		if apierrs.IsNotFound(err) {
+			if r.DryRun {
+				r.reportDryRun(namespace, FeatureMesh, OperationCreate, "create ServiceMeshMember", meshMemberChanges(nil, desiredMeshMember))
+
+				return nil
+			}
+
 			log.Info("Adding namespace to the mesh")
 
 			err = r.Create(ctx, desiredMeshMember)
@@ -41,6 +78,10 @@ func (r *OpenshiftServiceMeshReconciler) reconcileMeshMember(ctx context.Context
 				return errors.Wrap(err, "unable to create ServiceMeshMember")
 			}
 
+			meshMemberOperations.WithLabelValues(OperationCreate).Inc()
+			r.Recorder.Eventf(namespace, v1.EventTypeNormal, ReasonServiceMeshMemberCreated,
+				"Added to the mesh of control plane %s/%s", controlPlane.Namespace, controlPlane.Name)
+
 			justCreated = true
 		} else {
 			log.Error(err, "Unable to fetch the ServiceMeshMember")
@@ -49,8 +90,19 @@ func (r *OpenshiftServiceMeshReconciler) reconcileMeshMember(ctx context.Context
 		}
 	}
 
+	// Control plane of the existing member cannot be changed, so it has to be re-created
+	if !justCreated && foundMember.Spec.ControlPlaneRef != desiredMeshMember.Spec.ControlPlaneRef {
+		return r.migrateMeshMember(ctx, namespace, foundMember, desiredMeshMember)
+	}
+
 	// Reconcile the membership spec if it has been manually modified
 	if !justCreated && !compareMeshMembers(*desiredMeshMember, *foundMember) {
+		if r.DryRun {
+			r.reportDryRun(namespace, FeatureMesh, OperationDriftRepair, "restore ServiceMeshMember", meshMemberChanges(foundMember, desiredMeshMember))
+
+			return nil
+		}
+
 		log.Info("Reconciling ServiceMeshMember")
 
 		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
@@ -63,6 +115,7 @@ func (r *OpenshiftServiceMeshReconciler) reconcileMeshMember(ctx context.Context
 
 			foundMember.Spec = desiredMeshMember.Spec
 			foundMember.ObjectMeta.Labels = desiredMeshMember.ObjectMeta.Labels
+			foundMember.ObjectMeta.OwnerReferences = desiredMeshMember.ObjectMeta.OwnerReferences
 
 			return errors.Wrap(r.Update(ctx, foundMember), "failed updating ServiceMeshMember")
 		})
@@ -72,37 +125,185 @@ func (r *OpenshiftServiceMeshReconciler) reconcileMeshMember(ctx context.Context
 
 			return errors.Wrap(err, "unable to reconcile the ServiceMeshMember")
 		}
+
+		meshMemberOperations.WithLabelValues(OperationDriftRepair).Inc()
+		r.Recorder.Event(namespace, v1.EventTypeNormal, ReasonServiceMeshMemberReconciled, "ServiceMeshMember modified out of band has been restored")
 	}
 
+	if r.DryRun {
+		return nil
+	}
+
+	if justCreated {
+		return r.propagateMemberStatus(ctx, namespace, desiredMeshMember)
+	}
+
+	return r.propagateMemberStatus(ctx, namespace, foundMember)
+}
+
+func (r *OpenshiftServiceMeshReconciler) migrateMeshMember(ctx context.Context, namespace *v1.Namespace, foundMember, desiredMeshMember *maistrav1.ServiceMeshMember) error {
+	log := r.Log.WithValues("feature", "mesh", "namespace", desiredMeshMember.Namespace)
+
+	if r.DryRun {
+		r.reportDryRun(namespace, FeatureMesh, OperationUpdate, "move ServiceMeshMember to another control plane", meshMemberChanges(foundMember, desiredMeshMember))
+
+		return nil
+	}
+
+	if foundMember.DeletionTimestamp.IsZero() {
+		log.Info("Moving namespace to another control plane",
+			"from", foundMember.Spec.ControlPlaneRef, "to", desiredMeshMember.Spec.ControlPlaneRef)
+
+		if err := r.Delete(ctx, foundMember); err != nil && !apierrs.IsNotFound(err) {
+			log.Error(err, "Unable to delete ServiceMeshMember")
+
+			return errors.Wrap(err, "unable to delete ServiceMeshMember")
+		}
+	}
+
+	if err := r.Create(ctx, desiredMeshMember); err != nil {
+		if apierrs.IsAlreadyExists(err) {
+			return errors.Errorf("waiting for ServiceMeshMember in namespace %s to be removed before re-creating it", desiredMeshMember.Namespace)
+		}
+
+		log.Error(err, "Unable to create ServiceMeshMember")
+
+		return errors.Wrap(err, "unable to create ServiceMeshMember")
+	}
+
+	meshMemberOperations.WithLabelValues(OperationUpdate).Inc()
+	r.Recorder.Eventf(namespace, v1.EventTypeNormal, ReasonServiceMeshMemberMigrated, "Moved to the mesh of control plane %s/%s",
+		desiredMeshMember.Spec.ControlPlaneRef.Namespace, desiredMeshMember.Spec.ControlPlaneRef.Name)
+
 	return nil
 }
 
-func newServiceMeshMember(namespace *v1.Namespace) *maistrav1.ServiceMeshMember {
-	controlPlaneName := getControlPlaneName()
-	meshNamespace := getMeshNamespace()
+// removeMeshMember deletes the ServiceMeshMember created for the namespace, taking it out of the mesh.
+func (r *OpenshiftServiceMeshReconciler) removeMeshMember(ctx context.Context, namespace *v1.Namespace) error {
+	log := r.Log.WithValues("feature", "mesh", "namespace", namespace.Name)
+
+	if _, exists := namespace.Annotations[AnnotationMemberStatus]; exists {
+		delete(namespace.Annotations, AnnotationMemberStatus)
+
+		if err := r.Update(ctx, namespace); err != nil {
+			return errors.Wrap(err, "failed removing mesh member status from namespace")
+		}
+	}
+
+	foundMember := &maistrav1.ServiceMeshMember{}
+	if err := r.Get(ctx, types.NamespacedName{
+		Name:      serviceMeshMemberName,
+		Namespace: namespace.Name,
+	}, foundMember); err != nil {
+		if apierrs.IsNotFound(err) {
+			return nil
+		}
+
+		log.Error(err, "Unable to fetch the ServiceMeshMember")
+
+		return errors.Wrap(err, "unable to fetch the ServiceMeshMember")
+	}
+
+	if !isManagedByController(foundMember) {
+		log.Info("ServiceMeshMember is not managed by the controller, leaving it intact")
+
+		return nil
+	}
+
+	log.Info("Removing namespace from the mesh")
+
+	if err := r.Delete(ctx, foundMember); err != nil && !apierrs.IsNotFound(err) {
+		log.Error(err, "Unable to delete ServiceMeshMember")
+
+		return errors.Wrap(err, "unable to delete ServiceMeshMember")
+	}
+
+	meshMemberOperations.WithLabelValues(OperationDelete).Inc()
+	r.Recorder.Event(namespace, v1.EventTypeNormal, ReasonServiceMeshMemberRemoved, "Removed from the mesh")
+
+	return nil
+}
 
+func newServiceMeshMember(namespace *v1.Namespace, controlPlane maistrav1.ServiceMeshControlPlaneRef) *maistrav1.ServiceMeshMember {
 	smm := &maistrav1.ServiceMeshMember{
 		TypeMeta: metav1.TypeMeta{},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "default", // The name MUST be default, per the maistra docs
+			Name:      serviceMeshMemberName,
 			Namespace: namespace.Name,
+			Labels: map[string]string{
+				LabelManagedBy: ManagedByValue,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(namespace, v1.SchemeGroupVersion.WithKind("Namespace")),
+			},
 		},
 		Spec: maistrav1.ServiceMeshMemberSpec{
-			ControlPlaneRef: maistrav1.ServiceMeshControlPlaneRef{
-				Name:      controlPlaneName,
-				Namespace: meshNamespace,
-			},
+			ControlPlaneRef: controlPlane,
 		},
 	}
 
 	return smm
 }
 
+// controlPlaneRef resolves the control plane the namespace should be a member of. Control plane selected
+// through the namespace annotation takes precedence over the one defined in the mesh configuration, as long as
+// it is allowed. Otherwise the configured control plane is returned together with ControlPlaneNotAllowedError.
+func controlPlaneRef(namespace *v1.Namespace, config MeshConfig, allowed []maistrav1.ServiceMeshControlPlaneRef) (maistrav1.ServiceMeshControlPlaneRef, error) {
+	configured := controlPlaneRefOf(config)
+
+	value, selected := namespace.Annotations[AnnotationControlPlane]
+	if !selected {
+		return configured, nil
+	}
+
+	controlPlane, err := ParseControlPlaneRef(value)
+	if err != nil {
+		return maistrav1.ServiceMeshControlPlaneRef{}, err
+	}
+
+	if controlPlane == configured {
+		return controlPlane, nil
+	}
+
+	for i := range allowed {
+		if allowed[i] == controlPlane {
+			return controlPlane, nil
+		}
+	}
+
+	return configured, &ControlPlaneNotAllowedError{ControlPlane: controlPlane}
+}
+
+func controlPlaneRefOf(config MeshConfig) maistrav1.ServiceMeshControlPlaneRef {
+	return maistrav1.ServiceMeshControlPlaneRef{
+		Name:      config.ControlPlaneName,
+		Namespace: config.MeshNamespace,
+	}
+}
+
+// ParseControlPlaneRef parses control plane reference defined in the <namespace>/<name> format.
+func ParseControlPlaneRef(value string) (maistrav1.ServiceMeshControlPlaneRef, error) {
+	namespace, name, found := strings.Cut(value, "/")
+	if !found || len(validation.IsDNS1123Label(namespace)) > 0 || len(validation.IsDNS1123Subdomain(name)) > 0 {
+		return maistrav1.ServiceMeshControlPlaneRef{}, errors.Errorf("invalid control plane reference %q, expected <namespace>/<name>", value)
+	}
+
+	return maistrav1.ServiceMeshControlPlaneRef{
+		Name:      name,
+		Namespace: namespace,
+	}, nil
+}
+
 func compareMeshMembers(m1, m2 maistrav1.ServiceMeshMember) bool {
 	return reflect.DeepEqual(m1.ObjectMeta.Labels, m2.ObjectMeta.Labels) &&
+		reflect.DeepEqual(m1.ObjectMeta.OwnerReferences, m2.ObjectMeta.OwnerReferences) &&
 		reflect.DeepEqual(m1.Spec, m2.Spec)
 }
 
+func isManagedByController(object client.Object) bool {
+	return object.GetLabels()[LabelManagedBy] == ManagedByValue
+}
+
 func serviceMeshIsNotEnabled(meta metav1.ObjectMeta) bool {
 	serviceMeshAnnotation := meta.Annotations[AnnotationServiceMesh]
 	if serviceMeshAnnotation != "" {
@@ -113,28 +314,3 @@ func serviceMeshIsNotEnabled(meta metav1.ObjectMeta) bool {
 
 	return true
 }
-
-func (r *OpenshiftServiceMeshReconciler) findIstioIngress(ctx context.Context) (routev1.RouteList, error) {
-	meshNamespace := getMeshNamespace()
-
-	routes := routev1.RouteList{}
-	if err := r.List(ctx, &routes, &client.ListOptions{
-		LabelSelector: labels.SelectorFromSet(labels.Set{"app": "odh-dashboard"}),
-		Namespace:     meshNamespace,
-	}); err != nil {
-		r.Log.Error(err, "Unable to find matching gateway")
-
-		return routev1.RouteList{}, errors.Wrap(err, "unable to find matching gateway")
-	}
-
-	if len(routes.Items) == 0 {
-		route := &routev1.Route{}
-
-		return routes, apierrs.NewNotFound(schema.GroupResource{
-			Group:    route.GroupVersionKind().Group,
-			Resource: route.ResourceVersion,
-		}, "no-route-matching-label")
-	}
-
-	return routes, nil
-}
@@ -0,0 +1,22 @@
+package controllers
+
+// Reasons of the Events emitted on the namespaces.
+const (
+	ReasonServiceMeshMemberCreated    = "ServiceMeshMemberCreated"
+	ReasonServiceMeshMemberMigrated   = "ServiceMeshMemberMigrated"
+	ReasonServiceMeshMemberReconciled = "ServiceMeshMemberReconciled"
+	ReasonServiceMeshMemberRemoved    = "ServiceMeshMemberRemoved"
+	ReasonServiceMeshMemberReady      = "ServiceMeshMemberReady"
+	ReasonServiceMeshMemberRejected   = "ServiceMeshMemberRejected"
+	ReasonGatewayAnnotationsAdded     = "GatewayAnnotationsAdded"
+	ReasonGatewayRouteNotFound        = "GatewayRouteNotFound"
+	ReasonGatewayRouteSelectionFailed = "GatewayRouteSelectionFailed"
+	ReasonWaitingForControlPlane      = "WaitingForControlPlane"
+	ReasonControlPlaneNotAllowed      = "ControlPlaneNotAllowed"
+	ReasonReconcileFailed             = "ReconcileFailed"
+	ReasonCleanupFailed               = "CleanupFailed"
+	ReasonDryRun                      = "DryRun"
+	ReasonTemplateResourceApplied     = "TemplateResourceApplied"
+	ReasonTemplateResourceRemoved     = "TemplateResourceRemoved"
+	ReasonTemplateFailed              = "TemplateFailed"
+)
@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8serrs "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// ReconcileFunc provisions or tears down a part of the mesh setup in the namespace.
+type ReconcileFunc func(ctx context.Context, namespace *v1.Namespace) error
+
+// FeatureWatch is a resource which changes have to be reconciled by the feature.
+type FeatureWatch struct {
+	Object  client.Object
+	Handler handler.EventHandler
+}
+
+// Feature bundles the logic needed to provision a part of the mesh setup in the namespace
+// together with the logic needed to tear it down.
+type Feature struct {
+	Name string
+	// DependsOn lists names of the features which have to be reconciled before this one.
+	// Feature is blocked when any of them has not succeeded, and switched off when any of them is disabled.
+	DependsOn []string
+	// Requires lists optional APIs the feature needs. Feature is skipped until all of them are served by the cluster.
+	Requires []Capability
+	// Watches lists resources which changes requeue namespaces. They are watched once required APIs are available.
+	Watches   []FeatureWatch
+	Reconcile ReconcileFunc
+	Cleanup   ReconcileFunc
+}
+
+func (f Feature) requiredCapabilities() []string {
+	names := make([]string, 0, len(f.Requires))
+	for _, capability := range f.Requires {
+		names = append(names, capability.Name)
+	}
+
+	return names
+}
+
+// FeatureRegistry holds features reconciled for every mesh-aware namespace. Features are reconciled after
+// the ones they depend on, otherwise in the order of their registration.
+type FeatureRegistry struct {
+	mu       sync.RWMutex
+	features []Feature
+	disabled map[string]bool
+}
+
+// NewFeatureRegistry creates an empty registry, with all features enabled.
+func NewFeatureRegistry() *FeatureRegistry {
+	return &FeatureRegistry{
+		disabled: map[string]bool{},
+	}
+}
+
+// Register adds features to the registry. Names of the features have to be unique.
+func (f *FeatureRegistry) Register(features ...Feature) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, feature := range features {
+		if feature.Name == "" || feature.Reconcile == nil || feature.Cleanup == nil {
+			return errors.Errorf("feature %q has to define name, reconcile and cleanup", feature.Name)
+		}
+
+		if f.find(feature.Name) != nil {
+			return errors.Errorf("feature %q is already registered", feature.Name)
+		}
+
+		f.features = append(f.features, feature)
+	}
+
+	return nil
+}
+
+// Disable switches features off for all namespaces. Resources they created are cleaned up.
+func (f *FeatureRegistry) Disable(names ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, name := range names {
+		f.disabled[name] = true
+	}
+}
+
+// IsEnabled checks if the feature has not been switched off.
+func (f *FeatureRegistry) IsEnabled(name string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return !f.disabled[name]
+}
+
+// Features returns registered features in the order they should be reconciled.
+func (f *FeatureRegistry) Features() []Feature {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	features, err := sortByDependencies(f.features)
+	if err != nil {
+		// Cyclic dependencies are rejected by Validate, fall back to the order of registration
+		features = make([]Feature, len(f.features))
+		copy(features, f.features)
+	}
+
+	return features
+}
+
+// Validate checks if disabled features and dependencies of the features refer to registered ones.
+func (f *FeatureRegistry) Validate() error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var errs []error
+
+	for name := range f.disabled {
+		if f.find(name) == nil {
+			errs = append(errs, errors.Errorf("unknown feature %q cannot be disabled", name))
+		}
+	}
+
+	for _, feature := range f.features {
+		for _, dependency := range feature.DependsOn {
+			if f.find(dependency) == nil {
+				errs = append(errs, errors.Errorf("feature %q depends on unknown feature %q", feature.Name, dependency))
+			}
+		}
+	}
+
+	if _, err := sortByDependencies(f.features); err != nil {
+		errs = append(errs, err)
+	}
+
+	return k8serrs.NewAggregate(errs)
+}
+
+// sortByDependencies orders features so that each comes after the ones it depends on, keeping the order
+// of registration otherwise. Dependencies on unknown features are ignored.
+func sortByDependencies(features []Feature) ([]Feature, error) {
+	registered := map[string]bool{}
+	for _, feature := range features {
+		registered[feature.Name] = true
+	}
+
+	sorted := make([]Feature, 0, len(features))
+	placed := map[string]bool{}
+
+	for len(sorted) < len(features) {
+		progressed := false
+
+		for _, feature := range features {
+			if placed[feature.Name] || !dependenciesPlaced(feature, registered, placed) {
+				continue
+			}
+
+			sorted = append(sorted, feature)
+			placed[feature.Name] = true
+			progressed = true
+
+			break
+		}
+
+		if !progressed {
+			var cyclic []string
+
+			for _, feature := range features {
+				if !placed[feature.Name] {
+					cyclic = append(cyclic, feature.Name)
+				}
+			}
+
+			return nil, errors.Errorf("features %v have cyclic dependencies", cyclic)
+		}
+	}
+
+	return sorted, nil
+}
+
+func dependenciesPlaced(feature Feature, registered, placed map[string]bool) bool {
+	for _, dependency := range feature.DependsOn {
+		if registered[dependency] && !placed[dependency] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f *FeatureRegistry) find(name string) *Feature {
+	for i := range f.features {
+		if f.features[i].Name == name {
+			return &f.features[i]
+		}
+	}
+
+	return nil
+}
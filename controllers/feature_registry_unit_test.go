@@ -0,0 +1,119 @@
+package controllers_test
+
+import (
+	"context"
+
+	"github.com/opendatahub-io/odh-project-controller/controllers"
+	"github.com/opendatahub-io/odh-project-controller/test/labels"
+	corev1 "k8s.io/api/core/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Feature registry", Label(labels.Unit), func() {
+
+	noop := func(_ context.Context, _ *corev1.Namespace) error {
+		return nil
+	}
+
+	feature := func(name string, dependsOn ...string) controllers.Feature {
+		return controllers.Feature{Name: name, DependsOn: dependsOn, Reconcile: noop, Cleanup: noop}
+	}
+
+	featureNames := func(registry *controllers.FeatureRegistry) []string {
+		var names []string
+		for _, f := range registry.Features() {
+			names = append(names, f.Name)
+		}
+
+		return names
+	}
+
+	It("should keep features in the order of registration", func() {
+		// given
+		registry := controllers.NewFeatureRegistry()
+
+		// when
+		Expect(registry.Register(feature("mesh"), feature("gateway-annotations"))).To(Succeed())
+		Expect(registry.Register(feature("peer-authentication", "mesh"))).To(Succeed())
+
+		// then
+		Expect(featureNames(registry)).To(Equal([]string{"mesh", "gateway-annotations", "peer-authentication"}))
+		Expect(registry.Validate()).To(Succeed())
+	})
+
+	It("should order features after their dependencies", func() {
+		// given
+		registry := controllers.NewFeatureRegistry()
+
+		// when
+		Expect(registry.Register(feature("peer-authentication", "mesh"), feature("gateway-annotations"))).To(Succeed())
+		Expect(registry.Register(feature("mesh"))).To(Succeed())
+
+		// then
+		Expect(featureNames(registry)).To(Equal([]string{"gateway-annotations", "mesh", "peer-authentication"}))
+		Expect(registry.Validate()).To(Succeed())
+	})
+
+	It("should reject feature registered twice", func() {
+		// given
+		registry := controllers.NewFeatureRegistry()
+		Expect(registry.Register(feature("mesh"))).To(Succeed())
+
+		// when
+		err := registry.Register(feature("mesh"))
+
+		// then
+		Expect(err).To(MatchError(ContainSubstring("already registered")))
+	})
+
+	It("should reject feature without cleanup", func() {
+		// given
+		registry := controllers.NewFeatureRegistry()
+
+		// when
+		err := registry.Register(controllers.Feature{Name: "mesh", Reconcile: noop})
+
+		// then
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should switch off disabled feature", func() {
+		// given
+		registry := controllers.NewFeatureRegistry()
+		Expect(registry.Register(feature("mesh"), feature("gateway-annotations"))).To(Succeed())
+
+		// when
+		registry.Disable("gateway-annotations")
+
+		// then
+		Expect(registry.IsEnabled("mesh")).To(BeTrue())
+		Expect(registry.IsEnabled("gateway-annotations")).To(BeFalse())
+		Expect(registry.Validate()).To(Succeed())
+	})
+
+	DescribeTable("it should report invalid configuration",
+		func(configure func(registry *controllers.FeatureRegistry), expectedErr string) {
+			registry := controllers.NewFeatureRegistry()
+			configure(registry)
+			Expect(registry.Validate()).To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("for disabling unknown feature", func(registry *controllers.FeatureRegistry) {
+			Expect(registry.Register(feature("mesh"))).To(Succeed())
+			registry.Disable("non-existing")
+		}, `unknown feature "non-existing" cannot be disabled`),
+		Entry("for depending on unknown feature", func(registry *controllers.FeatureRegistry) {
+			Expect(registry.Register(feature("peer-authentication", "non-existing"))).To(Succeed())
+		}, `depends on unknown feature "non-existing"`),
+		Entry("for cyclic dependencies", func(registry *controllers.FeatureRegistry) {
+			Expect(registry.Register(
+				feature("mesh", "authorization-policy"),
+				feature("peer-authentication", "mesh"),
+				feature("authorization-policy", "peer-authentication"),
+				feature("gateway-annotations"),
+			)).To(Succeed())
+		}, "features [mesh peer-authentication authorization-policy] have cyclic dependencies"),
+	)
+
+})
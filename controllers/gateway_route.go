@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/pkg/errors"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RouteSelectionStrategy decides which Route is used to expose the gateway when more than one matches the selector.
+type RouteSelectionStrategy string
+
+const (
+	// RouteSelectionFirst picks the first Route returned by the API server.
+	RouteSelectionFirst RouteSelectionStrategy = "first"
+	// RouteSelectionOldest picks the Route created first.
+	RouteSelectionOldest RouteSelectionStrategy = "oldest"
+	// RouteSelectionPriority picks the Route with the highest value of LabelGatewayRoutePriority.
+	RouteSelectionPriority RouteSelectionStrategy = "priority"
+	// RouteSelectionUnique expects exactly one Route to match.
+	RouteSelectionUnique RouteSelectionStrategy = "unique"
+)
+
+// RouteSelectionStrategies lists all supported strategies.
+func RouteSelectionStrategies() []RouteSelectionStrategy {
+	return []RouteSelectionStrategy{RouteSelectionFirst, RouteSelectionOldest, RouteSelectionPriority, RouteSelectionUnique}
+}
+
+// IsValid checks if the strategy is supported.
+func (s RouteSelectionStrategy) IsValid() bool {
+	for _, strategy := range RouteSelectionStrategies() {
+		if s == strategy {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RouteSelectionError is returned when none of the matching routes can be picked to expose the gateway.
+type RouteSelectionError struct {
+	Reason string
+}
+
+func (e *RouteSelectionError) Error() string {
+	return "unable to select gateway route: " + e.Reason
+}
+
+func routeSelectionFailed(format string, args ...interface{}) *RouteSelectionError {
+	return &RouteSelectionError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// SelectGatewayRoute picks the Route out of the matching ones. Route explicitly named takes precedence,
+// otherwise the given strategy is used.
+func SelectGatewayRoute(routes []routev1.Route, strategy RouteSelectionStrategy, routeName string) (*routev1.Route, error) {
+	if len(routes) == 0 {
+		return nil, routeSelectionFailed("no matching routes to select from")
+	}
+
+	if routeName != "" {
+		for i := range routes {
+			if routes[i].Name == routeName {
+				return &routes[i], nil
+			}
+		}
+
+		return nil, routeSelectionFailed("route %q requested by the namespace does not match gateway route selector", routeName)
+	}
+
+	switch strategy {
+	case RouteSelectionFirst:
+		return &routes[0], nil
+	case RouteSelectionOldest:
+		return selectOldest(routes), nil
+	case RouteSelectionPriority:
+		return selectHighestPriority(routes)
+	case RouteSelectionUnique:
+		if len(routes) > 1 {
+			return nil, routeSelectionFailed("%d routes match gateway route selector, expected exactly one: %v", len(routes), routeNames(routes))
+		}
+
+		return &routes[0], nil
+	}
+
+	return nil, errors.Errorf("unknown route selection strategy %q", strategy)
+}
+
+func selectOldest(routes []routev1.Route) *routev1.Route {
+	candidates := make([]routev1.Route, len(routes))
+	copy(candidates, routes)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].CreationTimestamp.Equal(&candidates[j].CreationTimestamp) {
+			return candidates[i].Name < candidates[j].Name
+		}
+
+		return candidates[i].CreationTimestamp.Before(&candidates[j].CreationTimestamp)
+	})
+
+	return &candidates[0]
+}
+
+func selectHighestPriority(routes []routev1.Route) (*routev1.Route, error) {
+	var selected []routev1.Route
+
+	highest := 0
+
+	for i := range routes {
+		priority := 0
+
+		if value, found := routes[i].Labels[LabelGatewayRoutePriority]; found {
+			var err error
+			if priority, err = strconv.Atoi(value); err != nil {
+				return nil, routeSelectionFailed("route %q has invalid %s label %q", routes[i].Name, LabelGatewayRoutePriority, value)
+			}
+		}
+
+		switch {
+		case len(selected) == 0 || priority > highest:
+			highest = priority
+			selected = []routev1.Route{routes[i]}
+		case priority == highest:
+			selected = append(selected, routes[i])
+		}
+	}
+
+	if len(selected) > 1 {
+		return nil, routeSelectionFailed("routes %v share the highest priority %d", routeNames(selected), highest)
+	}
+
+	return &selected[0], nil
+}
+
+func routeNames(routes []routev1.Route) []string {
+	names := make([]string, 0, len(routes))
+	for i := range routes {
+		names = append(names, routes[i].Name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (r *OpenshiftServiceMeshReconciler) findIstioIngress(ctx context.Context, config MeshConfig, routeName string) (*routev1.Route, error) {
+	selector, err := labels.Parse(config.GatewayRouteSelector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid gateway route selector %q", config.GatewayRouteSelector)
+	}
+
+	routes := routev1.RouteList{}
+	if err := r.List(ctx, &routes, &client.ListOptions{
+		LabelSelector: selector,
+		Namespace:     config.gatewayRouteNamespace(),
+	}); err != nil {
+		r.Log.Error(err, "Unable to find matching gateway")
+
+		return nil, errors.Wrap(err, "unable to find matching gateway")
+	}
+
+	if len(routes.Items) == 0 {
+		route := &routev1.Route{}
+
+		return nil, apierrs.NewNotFound(schema.GroupResource{
+			Group:    route.GroupVersionKind().Group,
+			Resource: route.ResourceVersion,
+		}, "no-route-matching-label")
+	}
+
+	return SelectGatewayRoute(routes.Items, config.GatewayRouteStrategy, routeName)
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+
+	meshv1alpha1 "github.com/opendatahub-io/odh-project-controller/api/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	maistrav1 "maistra.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ServiceMeshMemberToNamespace maps events of ServiceMeshMembers created by the controller back to the namespace owning them,
+// so that out-of-band edits and deletions are healed straight away.
+func ServiceMeshMemberToNamespace(_ context.Context, object client.Object) []reconcile.Request {
+	if !isManagedByController(object) && !isOwnedByNamespace(object) {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: object.GetNamespace()}},
+	}
+}
+
+func isOwnedByNamespace(object client.Object) bool {
+	owner := metav1.GetControllerOfNoCopy(object)
+
+	return owner != nil &&
+		owner.APIVersion == v1.SchemeGroupVersion.String() &&
+		owner.Kind == "Namespace" &&
+		owner.Name == object.GetNamespace()
+}
+
+// meshConfigChanged reloads the mesh configuration when its ConfigMap changes. If the configuration in use
+// differs from the previous one, all mesh-aware namespaces are requeued so their resources get migrated.
+func (r *OpenshiftServiceMeshReconciler) meshConfigChanged(ctx context.Context, _ client.Object) []reconcile.Request {
+	source := r.Config.Source()
+
+	configMap := &v1.ConfigMap{}
+	if err := r.Get(ctx, source, configMap); err != nil {
+		if !apierrs.IsNotFound(err) {
+			r.Log.Error(err, "Unable to fetch mesh configuration", "configmap", source)
+
+			return nil
+		}
+
+		configMap = nil
+	}
+
+	changed, err := r.Config.Load(configMap)
+	if err != nil {
+		r.Log.Error(err, "Ignoring invalid mesh configuration", "configmap", source)
+
+		return nil
+	}
+
+	if !changed {
+		return nil
+	}
+
+	r.Log.Info("Mesh configuration changed, requeueing mesh-aware namespaces", "config", r.Config.Get())
+
+	return r.meshAwareNamespaceRequests(ctx)
+}
+
+// meshPolicyChanged requeues all mesh-aware namespaces, as any of them can be affected by the change of ProjectMeshPolicy.
+func (r *OpenshiftServiceMeshReconciler) meshPolicyChanged(ctx context.Context, _ client.Object) []reconcile.Request {
+	return r.meshAwareNamespaceRequests(ctx)
+}
+
+// gatewayRouteChanged requeues all mesh-aware namespaces when a Route in one of the namespaces
+// where gateway routes are looked up changes, so their gateway annotations are kept in sync.
+func (r *OpenshiftServiceMeshReconciler) gatewayRouteChanged(ctx context.Context, object client.Object) []reconcile.Request {
+	namespaces, err := r.gatewayRouteNamespaces(ctx)
+	if err != nil {
+		r.Log.Error(err, "Unable to determine gateway route namespaces")
+
+		return nil
+	}
+
+	if !namespaces.Has(object.GetNamespace()) {
+		return nil
+	}
+
+	return r.meshAwareNamespaceRequests(ctx)
+}
+
+// controlPlaneChanged requeues namespaces with pending enrolment when the ServiceMeshControlPlane becomes Ready.
+func (r *OpenshiftServiceMeshReconciler) controlPlaneChanged(ctx context.Context, object client.Object) []reconcile.Request {
+	smcp, ok := object.(*maistrav1.ServiceMeshControlPlane)
+	if !ok || !isControlPlaneReady(smcp) {
+		return nil
+	}
+
+	statuses := &meshv1alpha1.ProjectMeshStatusList{}
+	if err := r.List(ctx, statuses); err != nil {
+		r.Log.Error(err, "Unable to list mesh statuses")
+
+		return nil
+	}
+
+	var requests []reconcile.Request
+
+	for i := range statuses.Items {
+		if statuses.Items[i].Status.Phase == meshv1alpha1.MeshPhasePending {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: statuses.Items[i].Namespace},
+			})
+		}
+	}
+
+	return requests
+}
+
+func (r *OpenshiftServiceMeshReconciler) meshAwareNamespaceRequests(ctx context.Context) []reconcile.Request {
+	namespaces := &v1.NamespaceList{}
+	if err := r.List(ctx, namespaces); err != nil {
+		r.Log.Error(err, "Unable to list namespaces")
+
+		return nil
+	}
+
+	var requests []reconcile.Request
+
+	for i := range namespaces.Items {
+		if isMeshAware(&namespaces.Items[i], r.Reserved) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: namespaces.Items[i].Name},
+			})
+		}
+	}
+
+	return requests
+}
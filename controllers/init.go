@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	meshv1alpha1 "github.com/opendatahub-io/odh-project-controller/api/v1alpha1"
 	routev1 "github.com/openshift/api/route/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -13,4 +14,5 @@ func RegisterSchemes(s *runtime.Scheme) {
 	utilruntime.Must(clientgoscheme.AddToScheme(s))
 	utilruntime.Must(maistrav1.AddToScheme(s))
 	utilruntime.Must(routev1.Install(s))
+	utilruntime.Must(meshv1alpha1.AddToScheme(s))
 }
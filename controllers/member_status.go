@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	maistrav1 "maistra.io/api/core/v1"
+)
+
+// MemberState summarizes the ServiceMeshMember conditions reported by the mesh.
+type MemberState string
+
+const (
+	// MemberReady means the mesh accepted the namespace as its member.
+	MemberReady MemberState = "Ready"
+	// MemberPending means the mesh has not processed the membership yet.
+	MemberPending MemberState = "Pending"
+	// MemberRejected means the mesh failed to reconcile the membership, e.g. the control plane denied it.
+	MemberRejected MemberState = "Rejected"
+)
+
+// MemberRejectedError is returned when the mesh rejects the ServiceMeshMember of the namespace.
+type MemberRejectedError struct {
+	Summary string
+}
+
+func (e *MemberRejectedError) Error() string {
+	return "ServiceMeshMember rejected by the mesh: " + e.Summary
+}
+
+// SummarizeMemberConditions determines the state of the ServiceMeshMember and describes its conditions
+// in the form of "<state>: <type>=<status> (<reason>: <message>), ...".
+func SummarizeMemberConditions(member *maistrav1.ServiceMeshMember) (MemberState, string) {
+	state := MemberPending
+
+	conditions := make([]string, 0, len(member.Status.Conditions))
+
+	for _, condition := range member.Status.Conditions {
+		conditionType := string(condition.Type)
+		isTrue := string(condition.Status) == string(v1.ConditionTrue)
+		isFalse := string(condition.Status) == string(v1.ConditionFalse)
+
+		switch {
+		case conditionType == string(maistrav1.ConditionTypeMemberReconciled) && isFalse:
+			state = MemberRejected
+		case conditionType == string(maistrav1.ConditionTypeMemberReady) && isTrue && state != MemberRejected:
+			state = MemberReady
+		}
+
+		conditions = append(conditions, describeCondition(conditionType, string(condition.Status), condition.Reason, condition.Message))
+	}
+
+	if len(conditions) == 0 {
+		return state, string(state)
+	}
+
+	return state, fmt.Sprintf("%s: %s", state, strings.Join(conditions, ", "))
+}
+
+func describeCondition(conditionType, status, reason, message string) string {
+	description := conditionType + "=" + status
+
+	details := reason
+	if message != "" {
+		details = strings.TrimPrefix(reason+": "+message, ": ")
+	}
+
+	if details != "" {
+		description += " (" + details + ")"
+	}
+
+	return description
+}
+
+// propagateMemberStatus mirrors the state of the ServiceMeshMember onto the namespace annotation and announces its changes
+// through Events. Rejected membership is reported as an error, so that it is retried with backoff.
+func (r *OpenshiftServiceMeshReconciler) propagateMemberStatus(ctx context.Context, namespace *v1.Namespace, member *maistrav1.ServiceMeshMember) error {
+	state, summary := SummarizeMemberConditions(member)
+
+	if namespace.Annotations[AnnotationMemberStatus] != summary {
+		previousState, _, _ := strings.Cut(namespace.Annotations[AnnotationMemberStatus], ":")
+
+		namespace.Annotations[AnnotationMemberStatus] = summary
+		if err := r.Update(ctx, namespace); err != nil {
+			return errors.Wrap(err, "failed updating namespace with mesh member status")
+		}
+
+		if previousState != string(state) {
+			switch state {
+			case MemberReady:
+				r.Recorder.Event(namespace, v1.EventTypeNormal, ReasonServiceMeshMemberReady, summary)
+			case MemberRejected:
+				r.Recorder.Event(namespace, v1.EventTypeWarning, ReasonServiceMeshMemberRejected, summary)
+			case MemberPending:
+			}
+		}
+	}
+
+	if state == MemberRejected {
+		return &MemberRejectedError{Summary: summary}
+	}
+
+	return nil
+}
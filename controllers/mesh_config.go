@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	k8serrs "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	MeshNamespaceEnv         = "MESH_NAMESPACE"
+	ControlPlaneEnv          = "CONTROL_PLANE_NAME"
+	GatewayRouteSelectorEnv  = "GATEWAY_ROUTE_SELECTOR"
+	GatewayRouteNamespaceEnv = "GATEWAY_ROUTE_NAMESPACE"
+	GatewayRouteStrategyEnv  = "GATEWAY_ROUTE_STRATEGY"
+	MeshConfigMapName        = "service-mesh-refs"
+)
+
+// MeshConfig holds the settings of the mesh namespaces are enrolled to.
+type MeshConfig struct {
+	ControlPlaneName string
+	MeshNamespace    string
+	// GatewayRouteSelector is a label selector of the Route exposing Istio ingress gateway.
+	GatewayRouteSelector string
+	// GatewayRouteNamespace is the namespace the gateway Route is looked up in. Mesh namespace is used when empty.
+	GatewayRouteNamespace string
+	// GatewayRouteStrategy decides which Route is used when more than one matches the selector.
+	GatewayRouteStrategy RouteSelectionStrategy
+}
+
+// NewMeshConfigFromEnv reads the mesh settings from environment variables, falling back to defaults.
+func NewMeshConfigFromEnv() MeshConfig {
+	return MeshConfig{
+		ControlPlaneName:      getEnvOr(ControlPlaneEnv, "basic"),
+		MeshNamespace:         getEnvOr(MeshNamespaceEnv, "istio-system"),
+		GatewayRouteSelector:  getEnvOr(GatewayRouteSelectorEnv, "app=odh-dashboard"),
+		GatewayRouteNamespace: getEnvOr(GatewayRouteNamespaceEnv, ""),
+		GatewayRouteStrategy:  RouteSelectionStrategy(getEnvOr(GatewayRouteStrategyEnv, string(RouteSelectionOldest))),
+	}
+}
+
+// MeshConfigFromConfigMap reads the mesh settings from the ConfigMap. Keys absent in the ConfigMap
+// are taken from the provided defaults. Resulting config is validated before it is returned.
+func MeshConfigFromConfigMap(configMap *v1.ConfigMap, defaults MeshConfig) (MeshConfig, error) {
+	config := defaults
+
+	fields := map[string]*string{
+		ControlPlaneEnv:          &config.ControlPlaneName,
+		MeshNamespaceEnv:         &config.MeshNamespace,
+		GatewayRouteSelectorEnv:  &config.GatewayRouteSelector,
+		GatewayRouteNamespaceEnv: &config.GatewayRouteNamespace,
+		GatewayRouteStrategyEnv:  (*string)(&config.GatewayRouteStrategy),
+	}
+
+	for key, field := range fields {
+		if value, found := configMap.Data[key]; found {
+			*field = value
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return defaults, errors.Wrapf(err, "invalid mesh configuration in ConfigMap %s/%s", configMap.Namespace, configMap.Name)
+	}
+
+	return config, nil
+}
+
+// Validate ensures the config refers to resources which can exist in the cluster.
+func (c MeshConfig) Validate() error {
+	var errs []error
+
+	for _, msg := range validation.IsDNS1123Subdomain(c.ControlPlaneName) {
+		errs = append(errs, fmt.Errorf("%s %q: %s", ControlPlaneEnv, c.ControlPlaneName, msg))
+	}
+
+	for _, msg := range validation.IsDNS1123Label(c.MeshNamespace) {
+		errs = append(errs, fmt.Errorf("%s %q: %s", MeshNamespaceEnv, c.MeshNamespace, msg))
+	}
+
+	if _, err := labels.Parse(c.GatewayRouteSelector); err != nil {
+		errs = append(errs, errors.Wrapf(err, "%s %q", GatewayRouteSelectorEnv, c.GatewayRouteSelector))
+	}
+
+	if c.GatewayRouteNamespace != "" {
+		for _, msg := range validation.IsDNS1123Label(c.GatewayRouteNamespace) {
+			errs = append(errs, fmt.Errorf("%s %q: %s", GatewayRouteNamespaceEnv, c.GatewayRouteNamespace, msg))
+		}
+	}
+
+	if !c.GatewayRouteStrategy.IsValid() {
+		errs = append(errs, fmt.Errorf("%s %q: must be one of %v", GatewayRouteStrategyEnv, c.GatewayRouteStrategy, RouteSelectionStrategies()))
+	}
+
+	return k8serrs.NewAggregate(errs)
+}
+
+func (c MeshConfig) gatewayRouteNamespace() string {
+	if c.GatewayRouteNamespace != "" {
+		return c.GatewayRouteNamespace
+	}
+
+	return c.MeshNamespace
+}
+
+// MeshConfigStore holds the mesh configuration currently in use. It is safe to share between
+// concurrently running reconcilers.
+type MeshConfigStore struct {
+	mu      sync.RWMutex
+	base    MeshConfig
+	current MeshConfig
+	source  types.NamespacedName
+}
+
+// NewMeshConfigStore creates the store using base config until the ConfigMap pointed by source is loaded.
+// Base config is also restored when the ConfigMap is removed.
+func NewMeshConfigStore(base MeshConfig, source types.NamespacedName) *MeshConfigStore {
+	return &MeshConfigStore{
+		base:    base,
+		current: base,
+		source:  source,
+	}
+}
+
+// Get returns the mesh configuration currently in use.
+func (s *MeshConfigStore) Get() MeshConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.current
+}
+
+// Source returns the reference to the ConfigMap the configuration is loaded from.
+func (s *MeshConfigStore) Source() types.NamespacedName {
+	return s.source
+}
+
+// Load replaces the configuration with the one defined in the ConfigMap, or restores the base config
+// if ConfigMap is nil. Invalid configuration is rejected and the current one is kept.
+// Returns true if the configuration in use has changed.
+func (s *MeshConfigStore) Load(configMap *v1.ConfigMap) (bool, error) {
+	config := s.base
+
+	if configMap != nil {
+		var err error
+		if config, err = MeshConfigFromConfigMap(configMap, s.base); err != nil {
+			return false, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := s.current != config
+	s.current = config
+
+	return changed, nil
+}
+
+func getEnvOr(key, defaultValue string) string {
+	if env, defined := os.LookupEnv(key); defined {
+		return env
+	}
+
+	return defaultValue
+}
@@ -0,0 +1,120 @@
+package controllers_test
+
+import (
+	"github.com/opendatahub-io/odh-project-controller/controllers"
+	"github.com/opendatahub-io/odh-project-controller/test/labels"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Mesh configuration", Label(labels.Unit), func() {
+
+	defaults := controllers.MeshConfig{
+		ControlPlaneName:     "basic",
+		MeshNamespace:        "istio-system",
+		GatewayRouteSelector: "app=odh-dashboard",
+		GatewayRouteStrategy: controllers.RouteSelectionFirst,
+	}
+
+	configMapWith := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      controllers.MeshConfigMapName,
+				Namespace: "opendatahub",
+			},
+			Data: data,
+		}
+	}
+
+	When("Reading config map", func() {
+
+		It("should fall back to defaults for absent keys", func() {
+			// when
+			config, err := controllers.MeshConfigFromConfigMap(configMapWith(map[string]string{
+				controllers.ControlPlaneEnv: "minimal",
+			}), defaults)
+
+			// then
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.ControlPlaneName).To(Equal("minimal"))
+			Expect(config.MeshNamespace).To(Equal("istio-system"))
+		})
+
+		It("should read gateway route discovery settings", func() {
+			// when
+			config, err := controllers.MeshConfigFromConfigMap(configMapWith(map[string]string{
+				controllers.GatewayRouteSelectorEnv:  "istio=ingressgateway",
+				controllers.GatewayRouteNamespaceEnv: "ingress",
+				controllers.GatewayRouteStrategyEnv:  "oldest",
+			}), defaults)
+
+			// then
+			Expect(err).ToNot(HaveOccurred())
+			Expect(config.GatewayRouteSelector).To(Equal("istio=ingressgateway"))
+			Expect(config.GatewayRouteNamespace).To(Equal("ingress"))
+			Expect(config.GatewayRouteStrategy).To(Equal(controllers.RouteSelectionOldest))
+		})
+
+		DescribeTable("it should reject invalid values",
+			func(key, value string) {
+				_, err := controllers.MeshConfigFromConfigMap(configMapWith(map[string]string{key: value}), defaults)
+				Expect(err).To(MatchError(ContainSubstring(key)))
+			},
+			Entry("for mesh namespace", controllers.MeshNamespaceEnv, "Not_A_Namespace"),
+			Entry("for gateway route selector", controllers.GatewayRouteSelectorEnv, "app in (odh"),
+			Entry("for gateway route strategy", controllers.GatewayRouteStrategyEnv, "random"),
+		)
+
+	})
+
+	When("Loading config into the store", func() {
+
+		var store *controllers.MeshConfigStore
+
+		BeforeEach(func() {
+			store = controllers.NewMeshConfigStore(defaults, types.NamespacedName{Namespace: "opendatahub", Name: controllers.MeshConfigMapName})
+		})
+
+		It("should report change of the config in use", func() {
+			// when
+			changed, err := store.Load(configMapWith(map[string]string{controllers.ControlPlaneEnv: "minimal"}))
+
+			// then
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			Expect(store.Get().ControlPlaneName).To(Equal("minimal"))
+		})
+
+		It("should keep current config when the new one is invalid", func() {
+			// given
+			_, _ = store.Load(configMapWith(map[string]string{controllers.ControlPlaneEnv: "minimal"}))
+
+			// when
+			changed, err := store.Load(configMapWith(map[string]string{controllers.ControlPlaneEnv: "-invalid-"}))
+
+			// then
+			Expect(err).To(HaveOccurred())
+			Expect(changed).To(BeFalse())
+			Expect(store.Get().ControlPlaneName).To(Equal("minimal"))
+		})
+
+		It("should restore defaults when config map is removed", func() {
+			// given
+			_, _ = store.Load(configMapWith(map[string]string{controllers.ControlPlaneEnv: "minimal"}))
+
+			// when
+			changed, err := store.Load(nil)
+
+			// then
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			Expect(store.Get()).To(Equal(defaults))
+		})
+
+	})
+
+})
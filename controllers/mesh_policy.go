@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"context"
+
+	meshv1alpha1 "github.com/opendatahub-io/odh-project-controller/api/v1alpha1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	maistrav1 "maistra.io/api/core/v1"
+)
+
+// resolvePolicy finds the ProjectMeshPolicy applying to the namespace. When more than one policy matches,
+// the one with the highest priority wins, ties are resolved by the name of the policy.
+// Returns nil if there is no matching policy.
+func (r *OpenshiftServiceMeshReconciler) resolvePolicy(ctx context.Context, namespace *v1.Namespace) (*meshv1alpha1.ProjectMeshPolicy, error) {
+	policies := &meshv1alpha1.ProjectMeshPolicyList{}
+	if err := r.List(ctx, policies); err != nil {
+		return nil, errors.Wrap(err, "unable to list ProjectMeshPolicies")
+	}
+
+	var resolved *meshv1alpha1.ProjectMeshPolicy
+
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+
+		matches, err := policyMatches(policy, namespace)
+		if err != nil {
+			r.Log.Error(err, "Ignoring ProjectMeshPolicy with invalid namespace selector", "policy", policy.Name)
+
+			continue
+		}
+
+		if matches && (resolved == nil || takesPrecedence(policy, resolved)) {
+			resolved = policy
+		}
+	}
+
+	return resolved, nil
+}
+
+// meshConfigFor returns the mesh configuration for the namespace, that is the controller configuration
+// overridden by the settings of the matching ProjectMeshPolicy.
+func (r *OpenshiftServiceMeshReconciler) meshConfigFor(ctx context.Context, namespace *v1.Namespace) (MeshConfig, error) {
+	config := r.Config.Get()
+
+	policy, err := r.resolvePolicy(ctx, namespace)
+	if err != nil || policy == nil {
+		return config, err
+	}
+
+	return applyPolicy(config, policy)
+}
+
+// controlPlaneFor resolves the control plane the namespace should be a member of, allowing to select it through
+// the namespace annotation only when it is listed in the matching ProjectMeshPolicy.
+func (r *OpenshiftServiceMeshReconciler) controlPlaneFor(ctx context.Context, namespace *v1.Namespace, config MeshConfig) (maistrav1.ServiceMeshControlPlaneRef, error) {
+	policy, err := r.resolvePolicy(ctx, namespace)
+	if err != nil {
+		return maistrav1.ServiceMeshControlPlaneRef{}, err
+	}
+
+	return controlPlaneRef(namespace, config, allowedControlPlanes(policy))
+}
+
+// gatewayRouteNamespaces returns all namespaces in which gateway routes are looked up, considering all ProjectMeshPolicies.
+func (r *OpenshiftServiceMeshReconciler) gatewayRouteNamespaces(ctx context.Context) (sets.Set[string], error) {
+	config := r.Config.Get()
+	namespaces := sets.New(config.gatewayRouteNamespace())
+
+	policies := &meshv1alpha1.ProjectMeshPolicyList{}
+	if err := r.List(ctx, policies); err != nil {
+		return namespaces, errors.Wrap(err, "unable to list ProjectMeshPolicies")
+	}
+
+	for i := range policies.Items {
+		if policyConfig, err := applyPolicy(config, &policies.Items[i]); err == nil {
+			namespaces.Insert(policyConfig.gatewayRouteNamespace())
+		}
+	}
+
+	return namespaces, nil
+}
+
+func applyPolicy(config MeshConfig, policy *meshv1alpha1.ProjectMeshPolicy) (MeshConfig, error) {
+	if ref := policy.Spec.ControlPlaneRef; ref != nil {
+		config.ControlPlaneName = ref.Name
+		config.MeshNamespace = ref.Namespace
+	}
+
+	if policy.Spec.GatewayRouteSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(policy.Spec.GatewayRouteSelector)
+		if err != nil {
+			return config, errors.Wrapf(err, "invalid gateway route selector in ProjectMeshPolicy %s", policy.Name)
+		}
+
+		config.GatewayRouteSelector = selector.String()
+	}
+
+	return config, errors.Wrapf(config.Validate(), "invalid mesh configuration in ProjectMeshPolicy %s", policy.Name)
+}
+
+func allowedControlPlanes(policy *meshv1alpha1.ProjectMeshPolicy) []maistrav1.ServiceMeshControlPlaneRef {
+	if policy == nil {
+		return nil
+	}
+
+	allowed := make([]maistrav1.ServiceMeshControlPlaneRef, 0, len(policy.Spec.AllowedControlPlanes))
+	for _, ref := range policy.Spec.AllowedControlPlanes {
+		allowed = append(allowed, maistrav1.ServiceMeshControlPlaneRef{Name: ref.Name, Namespace: ref.Namespace})
+	}
+
+	return allowed
+}
+
+// isFeatureEnabled checks if the feature is enabled by the policy. All features are enabled when there is no policy,
+// or when the policy does not list any.
+func isFeatureEnabled(policy *meshv1alpha1.ProjectMeshPolicy, name string) bool {
+	if policy == nil || len(policy.Spec.Features) == 0 {
+		return true
+	}
+
+	for _, feature := range policy.Spec.Features {
+		if feature == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func policyMatches(policy *meshv1alpha1.ProjectMeshPolicy, namespace *v1.Namespace) (bool, error) {
+	if policy.Spec.NamespaceSelector == nil {
+		return true, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid namespace selector")
+	}
+
+	return selector.Matches(labels.Set(namespace.Labels)), nil
+}
+
+func takesPrecedence(policy, other *meshv1alpha1.ProjectMeshPolicy) bool {
+	if policy.Spec.Priority != other.Spec.Priority {
+		return policy.Spec.Priority > other.Spec.Priority
+	}
+
+	return policy.Name < other.Name
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+
+	meshv1alpha1 "github.com/opendatahub-io/odh-project-controller/api/v1alpha1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	maistrav1 "maistra.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// projectMeshStatusName is the name of the ProjectMeshStatus created in the namespace.
+const projectMeshStatusName = "default"
+
+// updateMeshStatus reports the outcome of the namespace reconciliation in its ProjectMeshStatus, creating it if needed.
+func (r *OpenshiftServiceMeshReconciler) updateMeshStatus(ctx context.Context, namespace *v1.Namespace,
+	features []meshv1alpha1.FeatureStatus, reconcileErr error,
+) error {
+	meshStatus := &meshv1alpha1.ProjectMeshStatus{}
+	if err := r.Get(ctx, types.NamespacedName{Name: projectMeshStatusName, Namespace: namespace.Name}, meshStatus); err != nil {
+		if !apierrs.IsNotFound(err) {
+			return errors.Wrap(err, "unable to fetch ProjectMeshStatus")
+		}
+
+		meshStatus = newProjectMeshStatus(namespace)
+		if err := r.Create(ctx, meshStatus); err != nil {
+			return errors.Wrap(err, "unable to create ProjectMeshStatus")
+		}
+	}
+
+	observed := r.observeMeshStatus(ctx, namespace, features, reconcileErr)
+	if reflect.DeepEqual(meshStatus.Status, observed) {
+		return nil
+	}
+
+	meshStatus.Status = observed
+
+	return errors.Wrap(r.Status().Update(ctx, meshStatus), "unable to update ProjectMeshStatus")
+}
+
+func (r *OpenshiftServiceMeshReconciler) observeMeshStatus(ctx context.Context, namespace *v1.Namespace,
+	features []meshv1alpha1.FeatureStatus, reconcileErr error,
+) meshv1alpha1.ProjectMeshStatusStatus {
+	observed := meshv1alpha1.ProjectMeshStatusStatus{
+		Features:                         features,
+		Gateway:                          namespace.Annotations[AnnotationPublicGatewayName],
+		GatewayHost:                      namespace.Annotations[AnnotationPublicGatewayExternalHost],
+		ObservedNamespaceResourceVersion: namespace.ResourceVersion,
+	}
+
+	member := &maistrav1.ServiceMeshMember{}
+	if err := r.Get(ctx, types.NamespacedName{Name: serviceMeshMemberName, Namespace: namespace.Name}, member); err == nil {
+		observed.ControlPlane = member.Spec.ControlPlaneRef.Namespace + "/" + member.Spec.ControlPlaneRef.Name
+		observed.MemberReady = isMeshMemberReady(member)
+	}
+
+	switch {
+	case reconcileErr != nil:
+		observed.Phase = meshv1alpha1.MeshPhaseFailed
+		observed.LastError = reconcileErr.Error()
+	case featureInState(features, meshv1alpha1.FeaturePending),
+		featureInState(features, meshv1alpha1.FeatureUnavailable),
+		featureInState(features, meshv1alpha1.FeatureBlocked):
+		observed.Phase = meshv1alpha1.MeshPhasePending
+	case observed.MemberReady || !featureSucceeded(features, FeatureMesh):
+		observed.Phase = meshv1alpha1.MeshPhaseReady
+	default:
+		observed.Phase = meshv1alpha1.MeshPhasePending
+	}
+
+	return observed
+}
+
+// removeMeshStatus deletes the ProjectMeshStatus of the namespace which is no longer part of the mesh.
+func (r *OpenshiftServiceMeshReconciler) removeMeshStatus(ctx context.Context, namespace *v1.Namespace) error {
+	// Namespaces which have never been in the mesh are torn down on every reconciliation, so the cache is
+	// consulted first to avoid a needless call to the API server
+	meshStatus := &meshv1alpha1.ProjectMeshStatus{}
+	if err := r.Get(ctx, types.NamespacedName{Name: projectMeshStatusName, Namespace: namespace.Name}, meshStatus); err != nil {
+		return errors.Wrap(client.IgnoreNotFound(err), "unable to fetch ProjectMeshStatus")
+	}
+
+	return errors.Wrap(client.IgnoreNotFound(r.Delete(ctx, meshStatus)), "unable to delete ProjectMeshStatus")
+}
+
+func newProjectMeshStatus(namespace *v1.Namespace) *meshv1alpha1.ProjectMeshStatus {
+	return &meshv1alpha1.ProjectMeshStatus{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      projectMeshStatusName,
+			Namespace: namespace.Name,
+			Labels: map[string]string{
+				LabelManagedBy: ManagedByValue,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(namespace, v1.SchemeGroupVersion.WithKind("Namespace")),
+			},
+		},
+	}
+}
+
+func newFeatureStatus(name string, state meshv1alpha1.FeatureState, err error) meshv1alpha1.FeatureStatus {
+	featureStatus := meshv1alpha1.FeatureStatus{Name: name, State: state}
+	if isPending(err) {
+		featureStatus.State = meshv1alpha1.FeaturePending
+		featureStatus.Message = err.Error()
+	} else if err != nil {
+		featureStatus.State = meshv1alpha1.FeatureFailed
+		featureStatus.Message = err.Error()
+	}
+
+	return featureStatus
+}
+
+func featureInState(features []meshv1alpha1.FeatureStatus, state meshv1alpha1.FeatureState) bool {
+	for _, feature := range features {
+		if feature.State == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+func featureSucceeded(features []meshv1alpha1.FeatureStatus, name string) bool {
+	for _, feature := range features {
+		if feature.Name == name {
+			return feature.State == meshv1alpha1.FeatureSucceeded
+		}
+	}
+
+	return false
+}
+
+func isMeshMemberReady(member *maistrav1.ServiceMeshMember) bool {
+	for _, condition := range member.Status.Conditions {
+		if string(condition.Type) == string(maistrav1.ConditionTypeMemberReady) {
+			return string(condition.Status) == string(v1.ConditionTrue)
+		}
+	}
+
+	return false
+}
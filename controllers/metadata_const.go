@@ -5,6 +5,17 @@ const (
 	AnnotationPublicGatewayName         = "service-mesh.opendatahub.io/public-gateway-name"
 	AnnotationPublicGatewayExternalHost = "service-mesh.opendatahub.io/public-gateway-host-external"
 	AnnotationPublicGatewayInternalHost = "service-mesh.opendatahub.io/public-gateway-host-internal"
+	AnnotationControlPlane              = "service-mesh.opendatahub.io/control-plane"
+	AnnotationGatewayRoute              = "service-mesh.opendatahub.io/gateway-route"
+	AnnotationPublicGatewayManaged      = "service-mesh.opendatahub.io/public-gateway-managed"
+	AnnotationMemberStatus              = "service-mesh.opendatahub.io/member-status"
+	AnnotationTemplateResources         = "service-mesh.opendatahub.io/template-resources"
 	LabelMaistraGatewayName             = "maistra.io/gateway-name"
 	LabelMaistraGatewayNamespace        = "maistra.io/gateway-namespace"
+	LabelGatewayRoutePriority           = "service-mesh.opendatahub.io/gateway-priority"
+	LabelTemplate                       = "service-mesh.opendatahub.io/template"
+	FinalizerServiceMesh                = "service-mesh.opendatahub.io/finalizer"
+	LabelReservedNamespace              = "service-mesh.opendatahub.io/reserved"
+	LabelManagedBy                      = "app.kubernetes.io/managed-by"
+	ManagedByValue                      = "odh-project-controller"
 )
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	maistrav1 "maistra.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "odh_project_controller"
+
+// Operations performed on ServiceMeshMembers, used as the operation label of meshMemberOperations.
+const (
+	OperationCreate      = "create"
+	OperationUpdate      = "update"
+	OperationDriftRepair = "drift_repair"
+	OperationDelete      = "delete"
+)
+
+var (
+	meshMemberOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "service_mesh_member_operations_total",
+		Help:      "Number of ServiceMeshMembers created, moved to another control plane, repaired after drift or deleted.",
+	}, []string{"operation"})
+
+	featureErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "feature_errors_total",
+		Help:      "Number of failed reconciliations of the feature.",
+	}, []string{"feature"})
+
+	featureReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "feature_reconcile_duration_seconds",
+		Help:      "Time taken to reconcile the feature for a namespace.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"feature"})
+
+	dryRunChanges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "dry_run_changes_total",
+		Help:      "Number of changes the feature would have made if the controller was not running in dry-run mode.",
+	}, []string{"feature", "operation"})
+)
+
+func init() { //nolint:gochecknoinits //reason this is how collectors are registered in controller-runtime metrics
+	metrics.Registry.MustRegister(meshMemberOperations, featureErrors, featureReconcileDuration, dryRunChanges)
+}
+
+// observeFeature records the duration and the outcome of the feature reconciliation.
+func observeFeature(name string, reconcile func() error) error {
+	start := time.Now()
+	err := reconcile()
+
+	featureReconcileDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+
+	if err != nil && !isPending(err) {
+		featureErrors.WithLabelValues(name).Inc()
+	}
+
+	return err
+}
+
+// enrolledNamespacesCollector reports the number of namespaces enrolled in each of the control planes,
+// based on the ServiceMeshMembers managed by the controller.
+type enrolledNamespacesCollector struct {
+	reader client.Reader
+	desc   *prometheus.Desc
+}
+
+// NewEnrolledNamespacesCollector creates collector of namespaces enrolled by the controller per control plane.
+func NewEnrolledNamespacesCollector(reader client.Reader) prometheus.Collector {
+	return &enrolledNamespacesCollector{
+		reader: reader,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "", "enrolled_namespaces"),
+			"Number of namespaces enrolled in the mesh per control plane.",
+			[]string{"control_plane"}, nil,
+		),
+	}
+}
+
+func (c *enrolledNamespacesCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- c.desc
+}
+
+func (c *enrolledNamespacesCollector) Collect(collected chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	members := &maistrav1.ServiceMeshMemberList{}
+	if err := c.reader.List(ctx, members, client.MatchingLabels{LabelManagedBy: ManagedByValue}); err != nil {
+		if meta.IsNoMatchError(err) {
+			// Service mesh is not installed, so there is nothing enrolled
+			return
+		}
+
+		collected <- prometheus.NewInvalidMetric(c.desc, err)
+
+		return
+	}
+
+	enrolled := map[string]int{}
+	for i := range members.Items {
+		controlPlane := members.Items[i].Spec.ControlPlaneRef
+		enrolled[controlPlane.Namespace+"/"+controlPlane.Name]++
+	}
+
+	for controlPlane, count := range enrolled {
+		collected <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), controlPlane)
+	}
+}
@@ -0,0 +1,142 @@
+package controllers_test
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/opendatahub-io/odh-project-controller/controllers"
+	"github.com/opendatahub-io/odh-project-controller/test/labels"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	maistrav1 "maistra.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Enrolled namespaces metric", Label(labels.Unit), func() {
+
+	meshMember := func(namespace, controlPlane string, managed bool) *maistrav1.ServiceMeshMember {
+		member := &maistrav1.ServiceMeshMember{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "default",
+				Namespace: namespace,
+			},
+			Spec: maistrav1.ServiceMeshMemberSpec{
+				ControlPlaneRef: maistrav1.ServiceMeshControlPlaneRef{Name: controlPlane, Namespace: "istio-system"},
+			},
+		}
+		if managed {
+			member.Labels = map[string]string{controllers.LabelManagedBy: controllers.ManagedByValue}
+		}
+
+		return member
+	}
+
+	It("should count namespaces enrolled by the controller per control plane", func() {
+		// given
+		scheme := runtime.NewScheme()
+		Expect(maistrav1.AddToScheme(scheme)).To(Succeed())
+
+		reader := fake.NewClientBuilder().
+			WithScheme(scheme).
+			WithObjects(
+				meshMember("project-a", "basic", true),
+				meshMember("project-b", "basic", true),
+				meshMember("project-c", "regulated", true),
+				meshMember("project-d", "basic", false),
+			).
+			Build()
+
+		// when
+		collector := controllers.NewEnrolledNamespacesCollector(reader)
+
+		// then
+		expected := `
+# HELP odh_project_controller_enrolled_namespaces Number of namespaces enrolled in the mesh per control plane.
+# TYPE odh_project_controller_enrolled_namespaces gauge
+odh_project_controller_enrolled_namespaces{control_plane="istio-system/basic"} 2
+odh_project_controller_enrolled_namespaces{control_plane="istio-system/regulated"} 1
+`
+		Expect(testutil.CollectAndCompare(collector, strings.NewReader(expected))).To(Succeed())
+	})
+
+})
+
+var _ = Describe("Service mesh member operations metric", Label(labels.Unit), func() {
+
+	deletedMembers := func() float64 {
+		families, err := metrics.Registry.Gather()
+		Expect(err).ToNot(HaveOccurred())
+
+		for _, family := range families {
+			if family.GetName() != "odh_project_controller_service_mesh_member_operations_total" {
+				continue
+			}
+
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "operation" && label.GetValue() == controllers.OperationDelete {
+						return metric.GetCounter().GetValue()
+					}
+				}
+			}
+		}
+
+		return 0
+	}
+
+	It("should count ServiceMeshMembers deleted when namespace opts out of the mesh", func() {
+		// given
+		scheme := runtime.NewScheme()
+		controllers.RegisterSchemes(scheme)
+
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "opted-out-ns",
+				Finalizers:  []string{controllers.FinalizerServiceMesh},
+				Annotations: map[string]string{controllers.AnnotationServiceMesh: "false"},
+			},
+		}
+		member := &maistrav1.ServiceMeshMember{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "default",
+				Namespace: namespace.Name,
+				Labels:    map[string]string{controllers.LabelManagedBy: controllers.ManagedByValue},
+			},
+		}
+
+		cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace, member).Build()
+
+		features := controllers.NewFeatureRegistry()
+		reconciler := &controllers.OpenshiftServiceMeshReconciler{
+			Client:       cli,
+			Scheme:       scheme,
+			Log:          logr.Discard(),
+			Config:       controllers.NewMeshConfigStore(controllers.NewMeshConfigFromEnv(), meshConfigSource),
+			Reserved:     controllers.DefaultReservedNamespaces(),
+			Recorder:     record.NewFakeRecorder(10),
+			Capabilities: controllers.AllCapabilities(),
+			Features:     features,
+		}
+		Expect(features.Register(reconciler.BuiltinFeatures()...)).To(Succeed())
+
+		deletedBefore := deletedMembers()
+
+		// when
+		_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: namespace.Name}})
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deletedMembers()).To(Equal(deletedBefore + 1))
+	})
+
+})
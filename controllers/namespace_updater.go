@@ -2,39 +2,225 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"strings"
 
+	routev1 "github.com/openshift/api/route/v1"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func (r *OpenshiftServiceMeshReconciler) addGatewayAnnotations(ctx context.Context, namespace *v1.Namespace) error {
-	if namespace.ObjectMeta.Annotations[AnnotationPublicGatewayExternalHost] != "" &&
-		namespace.ObjectMeta.Annotations[AnnotationPublicGatewayInternalHost] != "" &&
-		namespace.ObjectMeta.Annotations[AnnotationPublicGatewayName] != "" {
-		// If annotation is present we have nothing to do
-		return nil
+	managed, err := managedGatewayAnnotations(namespace)
+	if err != nil {
+		r.Log.Error(err, "Ignoring malformed record of managed gateway annotations.", "namespace", namespace.Name)
 	}
 
-	routes, err := r.findIstioIngress(ctx)
+	config, err := r.meshConfigFor(ctx, namespace)
 	if err != nil {
+		r.Log.Error(err, "Unable to resolve mesh configuration.")
+
+		return err
+	}
+
+	route, err := r.findIstioIngress(ctx, config, namespace.Annotations[AnnotationGatewayRoute])
+	if err != nil {
+		if gatewayAnnotationsSetByUser(namespace, managed) {
+			// Annotations set by hand do not depend on the route, nothing to update them with
+			r.Log.Info("Gateway route not resolved, keeping gateway annotations", "namespace", namespace.Name, "reason", err.Error())
+
+			return nil
+		}
+
 		r.Log.Error(err, "Unable to find matching istio ingress gateway.")
 
+		var selectionErr *RouteSelectionError
+
+		switch {
+		case apierrs.IsNotFound(err):
+			r.Recorder.Eventf(namespace, v1.EventTypeWarning, ReasonGatewayRouteNotFound,
+				"No route matching %q found in namespace %s", config.GatewayRouteSelector, config.gatewayRouteNamespace())
+		case errors.As(err, &selectionErr):
+			r.Recorder.Event(namespace, v1.EventTypeWarning, ReasonGatewayRouteSelectionFailed, selectionErr.Error())
+		}
+
 		return err
 	}
 
-	namespace.ObjectMeta.Annotations[AnnotationPublicGatewayExternalHost] = ExtractHostName(routes.Items[0].Spec.Host)
-	namespace.ObjectMeta.Annotations[AnnotationPublicGatewayInternalHost] = fmt.Sprintf("%s.%s.svc.cluster.local", routes.Items[0].Spec.To.Name, getMeshNamespace())
+	if r.DryRun {
+		updated := namespace.DeepCopy()
+		if syncGatewayAnnotations(updated, gatewayAnnotationsFor(route), managed) {
+			r.reportDryRun(namespace, FeatureGatewayAnnotations, OperationUpdate, "set gateway annotations",
+				mapChanges("metadata.annotations", namespace.Annotations, updated.Annotations))
+		}
+
+		return nil
+	}
+
+	if !syncGatewayAnnotations(namespace, gatewayAnnotationsFor(route), managed) {
+		return nil
+	}
+
+	if err := r.Client.Update(ctx, namespace); err != nil {
+		return errors.Wrap(err, "failed updating namespace with annotations")
+	}
+
+	r.Recorder.Eventf(namespace, v1.EventTypeNormal, ReasonGatewayAnnotationsAdded, "Gateway annotations set from route %s/%s", route.Namespace, route.Name)
+
+	return nil
+}
+
+// gatewayAnnotationsSetByUser checks if all gateway annotations are set, none of them recorded as written by the controller.
+// Such annotations are either set by hand or by the controller version which did not keep the record.
+func gatewayAnnotationsSetByUser(namespace *v1.Namespace, managed map[string]string) bool {
+	return len(managed) == 0 &&
+		namespace.ObjectMeta.Annotations[AnnotationPublicGatewayExternalHost] != "" &&
+		namespace.ObjectMeta.Annotations[AnnotationPublicGatewayInternalHost] != "" &&
+		namespace.ObjectMeta.Annotations[AnnotationPublicGatewayName] != ""
+}
+
+func gatewayAnnotationsFor(route *routev1.Route) map[string]string {
+	annotations := map[string]string{
+		AnnotationPublicGatewayExternalHost: ExtractHostName(route.Spec.Host),
+		AnnotationPublicGatewayInternalHost: fmt.Sprintf("%s.%s.svc.cluster.local", route.Spec.To.Name, route.Namespace),
+	}
+
+	if gateway := extractGateway(route.ObjectMeta); gateway != "" {
+		annotations[AnnotationPublicGatewayName] = gateway
+	}
+
+	return annotations
+}
+
+// syncGatewayAnnotations sets desired gateway annotations on the namespace. Values written by the controller before,
+// as recorded in AnnotationPublicGatewayManaged, are kept up to date or removed when no longer desired, while values changed
+// or set by the user are left alone. Returns true if the namespace has been changed.
+func syncGatewayAnnotations(namespace *v1.Namespace, desired, managed map[string]string) bool {
+	if namespace.ObjectMeta.Annotations == nil {
+		namespace.ObjectMeta.Annotations = map[string]string{}
+	}
+
+	owned := map[string]string{}
+
+	for annotation, value := range desired {
+		current, exists := namespace.ObjectMeta.Annotations[annotation]
+		lastWritten, isManaged := managed[annotation]
+
+		switch {
+		case !exists, isManaged && current == lastWritten, current == value:
+			namespace.ObjectMeta.Annotations[annotation] = value
+			owned[annotation] = value
+		}
+	}
+
+	changed := false
+
+	// Annotations no longer derived from the route, e.g. when it lost gateway labels, are removed unless changed by the user
+	for annotation, lastWritten := range managed {
+		if _, stillDesired := desired[annotation]; stillDesired {
+			continue
+		}
+
+		if current, exists := namespace.ObjectMeta.Annotations[annotation]; exists && current == lastWritten {
+			delete(namespace.ObjectMeta.Annotations, annotation)
+
+			changed = true
+		}
+	}
+
+	for annotation, value := range owned {
+		if managed[annotation] != value {
+			changed = true
+		}
+	}
+
+	if _, recorded := namespace.ObjectMeta.Annotations[AnnotationPublicGatewayManaged]; !recorded || len(owned) != len(managed) {
+		changed = true
+	}
+
+	if !changed {
+		return false
+	}
+
+	record, _ := json.Marshal(owned) //nolint:errchkjson //reason map of strings is always serializable
+	namespace.ObjectMeta.Annotations[AnnotationPublicGatewayManaged] = string(record)
+
+	return true
+}
+
+// managedGatewayAnnotations reads gateway annotation values previously written by the controller.
+func managedGatewayAnnotations(namespace *v1.Namespace) (map[string]string, error) {
+	managed := map[string]string{}
+
+	record, exists := namespace.ObjectMeta.Annotations[AnnotationPublicGatewayManaged]
+	if !exists {
+		return managed, nil
+	}
+
+	if err := json.Unmarshal([]byte(record), &managed); err != nil {
+		return map[string]string{}, errors.Wrapf(err, "invalid %s annotation", AnnotationPublicGatewayManaged)
+	}
+
+	return managed, nil
+}
+
+// removeGatewayAnnotations strips the public gateway annotations previously added by addGatewayAnnotations.
+// Annotations not recorded as managed, e.g. added by the controller version which did not keep the record,
+// are removed as well when they match the gateway route. Annotations set or changed by the user are left intact.
+func (r *OpenshiftServiceMeshReconciler) removeGatewayAnnotations(ctx context.Context, namespace *v1.Namespace) error {
+	managed, err := managedGatewayAnnotations(namespace)
+	if err != nil {
+		r.Log.Error(err, "Ignoring malformed record of managed gateway annotations.", "namespace", namespace.Name)
+	}
 
-	gateway := extractGateway(routes.Items[0].ObjectMeta)
-	if gateway != "" {
-		namespace.ObjectMeta.Annotations[AnnotationPublicGatewayName] = gateway
+	for annotation, value := range r.routeGatewayAnnotations(ctx, namespace) {
+		if _, recorded := managed[annotation]; !recorded && namespace.ObjectMeta.Annotations[annotation] == value {
+			managed[annotation] = value
+		}
+	}
+
+	removed := false
+
+	for _, annotation := range []string{AnnotationPublicGatewayName, AnnotationPublicGatewayExternalHost, AnnotationPublicGatewayInternalHost} {
+		lastWritten, isManaged := managed[annotation]
+		if current, exists := namespace.ObjectMeta.Annotations[annotation]; exists && isManaged && current == lastWritten {
+			delete(namespace.ObjectMeta.Annotations, annotation)
+
+			removed = true
+		}
+	}
+
+	if _, exists := namespace.ObjectMeta.Annotations[AnnotationPublicGatewayManaged]; exists {
+		delete(namespace.ObjectMeta.Annotations, AnnotationPublicGatewayManaged)
+
+		removed = true
+	}
+
+	if !removed {
+		return nil
+	}
+
+	return errors.Wrap(r.Client.Update(ctx, namespace), "failed removing gateway annotations from namespace")
+}
+
+// routeGatewayAnnotations returns gateway annotations derived from the route currently exposing the gateway,
+// or none when the route cannot be resolved.
+func (r *OpenshiftServiceMeshReconciler) routeGatewayAnnotations(ctx context.Context, namespace *v1.Namespace) map[string]string {
+	config, err := r.meshConfigFor(ctx, namespace)
+	if err != nil {
+		return nil
+	}
+
+	route, err := r.findIstioIngress(ctx, config, namespace.Annotations[AnnotationGatewayRoute])
+	if err != nil {
+		return nil
 	}
 
-	return errors.Wrap(r.Client.Update(ctx, namespace), "failed updating namespace with annotations")
+	return gatewayAnnotationsFor(route)
 }
 
 func extractGateway(meta metav1.ObjectMeta) string {
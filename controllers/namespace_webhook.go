@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate--v1-namespace,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=namespaces,verbs=create;update,versions=v1,name=vnamespace.service-mesh.opendatahub.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/mutate--v1-namespace,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=namespaces,verbs=create,versions=v1,name=mnamespace.service-mesh.opendatahub.io,admissionReviewVersions=v1
+
+// SetupWebhooksWithManager registers webhooks validating mesh annotations of namespaces and setting their gateway
+// annotations. Has to be called after SetupWithManager, as the webhooks share configuration with the controller.
+func (r *OpenshiftServiceMeshReconciler) SetupWebhooksWithManager(mgr ctrl.Manager) error {
+	return errors.Wrap(ctrl.NewWebhookManagedBy(mgr).
+		For(&v1.Namespace{}).
+		WithValidator(&NamespaceValidator{}).
+		WithDefaulter(NewNamespaceDefaulter(r)).
+		Complete(), "failed registering namespace webhooks")
+}
+
+// NamespaceDefaulter sets gateway annotations on mesh-aware namespaces when they are created, so that workloads
+// deployed right away can rely on them. The controller keeps them in sync afterwards. Namespace is admitted
+// without the annotations when they cannot be determined, leaving it to the controller to set them later.
+type NamespaceDefaulter struct {
+	reconciler *OpenshiftServiceMeshReconciler
+}
+
+var _ admission.CustomDefaulter = &NamespaceDefaulter{}
+
+// NewNamespaceDefaulter creates defaulter resolving gateway annotations the same way as the controller does.
+func NewNamespaceDefaulter(reconciler *OpenshiftServiceMeshReconciler) *NamespaceDefaulter {
+	return &NamespaceDefaulter{reconciler: reconciler}
+}
+
+// Default sets gateway annotations on the namespace.
+func (d *NamespaceDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	namespace, ok := obj.(*v1.Namespace)
+	if !ok {
+		return errors.Errorf("expected Namespace, got %T", obj)
+	}
+
+	r := d.reconciler
+	log := r.Log.WithValues("webhook", "namespace", "namespace", namespace.Name)
+
+	if serviceMeshIsNotEnabled(namespace.ObjectMeta) || r.Reserved.IsReserved(namespace) ||
+		!r.Capabilities.Has(CapabilityRoutes) || !r.Features.IsEnabled(FeatureGatewayAnnotations) {
+		return nil
+	}
+
+	managed, err := managedGatewayAnnotations(namespace)
+	if err != nil || gatewayAnnotationsSetByUser(namespace, managed) {
+		return nil
+	}
+
+	policy, err := r.resolvePolicy(ctx, namespace)
+	if err != nil {
+		log.Error(err, "Unable to resolve mesh policy, leaving gateway annotations to the controller")
+
+		return nil
+	}
+
+	if !isFeatureEnabled(policy, FeatureGatewayAnnotations) {
+		return nil
+	}
+
+	config, err := r.meshConfigFor(ctx, namespace)
+	if err != nil {
+		log.Error(err, "Unable to resolve mesh configuration, leaving gateway annotations to the controller")
+
+		return nil
+	}
+
+	route, err := r.findIstioIngress(ctx, config, namespace.Annotations[AnnotationGatewayRoute])
+	if err != nil {
+		log.Info("Unable to find gateway route, leaving gateway annotations to the controller", "reason", err.Error())
+
+		return nil
+	}
+
+	if r.DryRun {
+		log.Info("Dry run, not setting gateway annotations", "route", route.Namespace+"/"+route.Name)
+
+		return nil
+	}
+
+	syncGatewayAnnotations(namespace, gatewayAnnotationsFor(route), managed)
+
+	return nil
+}
+
+// NamespaceValidator rejects namespaces with malformed mesh annotations. Failing webhook does not block namespaces,
+// as malformed annotations are also tolerated by the controller.
+type NamespaceValidator struct{}
+
+var _ admission.CustomValidator = &NamespaceValidator{}
+
+// ValidateCreate checks mesh annotations of the new namespace.
+func (v *NamespaceValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	namespace, ok := obj.(*v1.Namespace)
+	if !ok {
+		return nil, errors.Errorf("expected Namespace, got %T", obj)
+	}
+
+	return nil, invalidNamespace(namespace, ValidateMeshAnnotations(namespace, nil))
+}
+
+// ValidateUpdate checks mesh annotations changed by the update.
+func (v *NamespaceValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldNamespace, ok := oldObj.(*v1.Namespace)
+	if !ok {
+		return nil, errors.Errorf("expected Namespace, got %T", oldObj)
+	}
+
+	namespace, ok := newObj.(*v1.Namespace)
+	if !ok {
+		return nil, errors.Errorf("expected Namespace, got %T", newObj)
+	}
+
+	return nil, invalidNamespace(namespace, ValidateMeshAnnotations(namespace, oldNamespace))
+}
+
+// ValidateDelete allows namespaces to be deleted regardless of their annotations.
+func (v *NamespaceValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func invalidNamespace(namespace *v1.Namespace, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return apierrs.NewInvalid(v1.SchemeGroupVersion.WithKind("Namespace").GroupKind(), namespace.Name, errs)
+}
+
+// ValidateMeshAnnotations checks values of the mesh annotations of the namespace. When the previous version
+// of the namespace is given, only annotations which have changed are checked, so that namespaces annotated
+// before the validation was in place can still be updated.
+func ValidateMeshAnnotations(namespace, old *v1.Namespace) field.ErrorList {
+	validators := []struct {
+		annotation string
+		validate   func(value string) string
+	}{
+		{AnnotationServiceMesh, validateBool},
+		{AnnotationControlPlane, validateControlPlaneRef},
+		{AnnotationGatewayRoute, validateRouteName},
+		{AnnotationPublicGatewayName, validateGatewayName},
+	}
+
+	annotationsPath := field.NewPath("metadata", "annotations")
+
+	var errs field.ErrorList
+
+	for _, validator := range validators {
+		annotation := validator.annotation
+
+		value, exists := namespace.Annotations[annotation]
+		if !exists {
+			continue
+		}
+
+		if old != nil {
+			if oldValue, existed := old.Annotations[annotation]; existed && oldValue == value {
+				continue
+			}
+		}
+
+		if msg := validator.validate(value); msg != "" {
+			errs = append(errs, field.Invalid(annotationsPath.Key(annotation), value, msg))
+		}
+	}
+
+	return errs
+}
+
+func validateBool(value string) string {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return `must be either "true" or "false"`
+	}
+
+	return ""
+}
+
+func validateGatewayName(value string) string {
+	msg := `must be the name of the gateway, optionally prefixed with its namespace, e.g. "opendatahub/odh-gateway"`
+
+	gatewayNamespace, name, qualified := strings.Cut(value, "/")
+	if !qualified {
+		name = gatewayNamespace
+	} else if len(validation.IsDNS1123Label(gatewayNamespace)) > 0 {
+		return msg
+	}
+
+	if len(validation.IsDNS1123Subdomain(name)) > 0 {
+		return msg
+	}
+
+	return ""
+}
+
+func validateControlPlaneRef(value string) string {
+	if _, err := ParseControlPlaneRef(value); err != nil {
+		return `must refer to the control plane as <namespace>/<name>, e.g. "istio-system/basic"`
+	}
+
+	return ""
+}
+
+func validateRouteName(value string) string {
+	if len(validation.IsDNS1123Subdomain(value)) > 0 {
+		return "must be the name of the Route exposing Istio ingress gateway"
+	}
+
+	return ""
+}
@@ -0,0 +1,217 @@
+package controllers_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/opendatahub-io/odh-project-controller/controllers"
+	"github.com/opendatahub-io/odh-project-controller/test/labels"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Namespace validation webhook", Label(labels.Unit), func() {
+
+	validator := &controllers.NamespaceValidator{}
+
+	namespaceWith := func(annotations map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "validated-ns",
+				Annotations: annotations,
+			},
+		}
+	}
+
+	DescribeTable("it should accept well-formed mesh annotations",
+		func(annotation, value string) {
+			_, err := validator.ValidateCreate(context.Background(), namespaceWith(map[string]string{annotation: value}))
+			Expect(err).ToNot(HaveOccurred())
+		},
+		Entry("for enabled service mesh", controllers.AnnotationServiceMesh, "true"),
+		Entry("for disabled service mesh", controllers.AnnotationServiceMesh, "false"),
+		Entry("for gateway name", controllers.AnnotationPublicGatewayName, "odh-gateway"),
+		Entry("for gateway name with namespace", controllers.AnnotationPublicGatewayName, "opendatahub/odh-gateway"),
+		Entry("for control plane", controllers.AnnotationControlPlane, "istio-system/basic"),
+		Entry("for gateway route", controllers.AnnotationGatewayRoute, "odh-dashboard"),
+	)
+
+	DescribeTable("it should reject malformed mesh annotations",
+		func(annotation, value, expectedMsg string) {
+			_, err := validator.ValidateCreate(context.Background(), namespaceWith(map[string]string{annotation: value}))
+			Expect(apierrs.IsInvalid(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring(annotation)))
+			Expect(err).To(MatchError(ContainSubstring(expectedMsg)))
+		},
+		Entry("for service mesh enabled with yes", controllers.AnnotationServiceMesh, "yes", `must be either "true" or "false"`),
+		Entry("for gateway name with too many segments", controllers.AnnotationPublicGatewayName, "opendatahub/odh/gateway", "optionally prefixed with its namespace"),
+		Entry("for gateway name with invalid namespace", controllers.AnnotationPublicGatewayName, "Open_Data_Hub/odh-gateway", "optionally prefixed with its namespace"),
+		Entry("for control plane without namespace", controllers.AnnotationControlPlane, "basic", "<namespace>/<name>"),
+		Entry("for invalid gateway route", controllers.AnnotationGatewayRoute, "ODH Dashboard", "must be the name of the Route"),
+	)
+
+	It("should report every malformed annotation", func() {
+		// given
+		namespace := namespaceWith(map[string]string{
+			controllers.AnnotationServiceMesh:       "yes",
+			controllers.AnnotationPublicGatewayName: "opendatahub/odh/gateway",
+		})
+
+		// when
+		_, err := validator.ValidateCreate(context.Background(), namespace)
+
+		// then
+		Expect(err).To(MatchError(SatisfyAll(
+			ContainSubstring(controllers.AnnotationServiceMesh),
+			ContainSubstring(controllers.AnnotationPublicGatewayName),
+		)))
+	})
+
+	It("should allow updates of namespace which was malformed before", func() {
+		// given
+		old := namespaceWith(map[string]string{controllers.AnnotationServiceMesh: "yes"})
+		updated := namespaceWith(map[string]string{controllers.AnnotationServiceMesh: "yes", "owner": "data-science"})
+
+		// when
+		_, err := validator.ValidateUpdate(context.Background(), old, updated)
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("should reject update introducing malformed annotation", func() {
+		// given
+		old := namespaceWith(map[string]string{controllers.AnnotationServiceMesh: "true"})
+		updated := namespaceWith(map[string]string{controllers.AnnotationServiceMesh: "enabled"})
+
+		// when
+		_, err := validator.ValidateUpdate(context.Background(), old, updated)
+
+		// then
+		Expect(apierrs.IsInvalid(err)).To(BeTrue())
+	})
+
+})
+
+var _ = Describe("Namespace defaulting webhook", Label(labels.Unit), func() {
+
+	var defaulter *controllers.NamespaceDefaulter
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		controllers.RegisterSchemes(scheme)
+
+		route := &routev1.Route{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "odh-gateway",
+				Namespace: "istio-system",
+				Labels: map[string]string{
+					"app":                                    "odh-dashboard",
+					controllers.LabelMaistraGatewayName:      "odh-gateway",
+					controllers.LabelMaistraGatewayNamespace: "opendatahub",
+				},
+			},
+			Spec: routev1.RouteSpec{
+				Host: "istio.io",
+				To:   routev1.RouteTargetReference{Name: "istio-ingressgateway"},
+			},
+		}
+
+		features := controllers.NewFeatureRegistry()
+		reconciler := &controllers.OpenshiftServiceMeshReconciler{
+			Client:       fake.NewClientBuilder().WithScheme(scheme).WithObjects(route).Build(),
+			Scheme:       scheme,
+			Log:          logr.Discard(),
+			Config:       controllers.NewMeshConfigStore(controllers.NewMeshConfigFromEnv(), meshConfigSource),
+			Reserved:     controllers.DefaultReservedNamespaces(),
+			Capabilities: controllers.AllCapabilities(),
+			Features:     features,
+		}
+		Expect(features.Register(reconciler.BuiltinFeatures()...)).To(Succeed())
+
+		defaulter = controllers.NewNamespaceDefaulter(reconciler)
+	})
+
+	It("should set gateway annotations on created mesh-aware namespace", func() {
+		// given
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "defaulted-ns",
+				Annotations: map[string]string{controllers.AnnotationServiceMesh: "true"},
+			},
+		}
+
+		// when
+		Expect(defaulter.Default(context.Background(), namespace)).To(Succeed())
+
+		// then
+		Expect(namespace.Annotations).To(SatisfyAll(
+			HaveKeyWithValue(controllers.AnnotationPublicGatewayName, "opendatahub/odh-gateway"),
+			HaveKeyWithValue(controllers.AnnotationPublicGatewayExternalHost, "istio.io"),
+			HaveKeyWithValue(controllers.AnnotationPublicGatewayInternalHost, "istio-ingressgateway.istio-system.svc.cluster.local"),
+			HaveKey(controllers.AnnotationPublicGatewayManaged),
+		))
+	})
+
+	It("should keep gateway annotation set by the user", func() {
+		// given
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "defaulted-ns",
+				Annotations: map[string]string{
+					controllers.AnnotationServiceMesh:               "true",
+					controllers.AnnotationPublicGatewayExternalHost: "custom.io",
+				},
+			},
+		}
+
+		// when
+		Expect(defaulter.Default(context.Background(), namespace)).To(Succeed())
+
+		// then
+		Expect(namespace.Annotations).To(HaveKeyWithValue(controllers.AnnotationPublicGatewayExternalHost, "custom.io"))
+		Expect(namespace.Annotations).To(HaveKeyWithValue(controllers.AnnotationPublicGatewayName, "opendatahub/odh-gateway"))
+	})
+
+	It("should leave namespace intact when gateway route cannot be found", func() {
+		// given
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "defaulted-ns",
+				Annotations: map[string]string{
+					controllers.AnnotationServiceMesh:  "true",
+					controllers.AnnotationGatewayRoute: "non-existing",
+				},
+			},
+		}
+
+		// when
+		Expect(defaulter.Default(context.Background(), namespace)).To(Succeed())
+
+		// then
+		Expect(namespace.Annotations).ToNot(HaveKey(controllers.AnnotationPublicGatewayName))
+	})
+
+	It("should not set gateway annotations on namespace which is not mesh-aware", func() {
+		// given
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "defaulted-ns",
+			},
+		}
+
+		// when
+		Expect(defaulter.Default(context.Background(), namespace)).To(Succeed())
+
+		// then
+		Expect(namespace.Annotations).To(BeEmpty())
+	})
+
+})
@@ -1,16 +1,16 @@
 package controllers
 
 import (
-	"regexp"
-
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
-func MeshAwareNamespaces() predicate.Funcs {
+func MeshAwareNamespaces(reserved *ReservedNamespaces) predicate.Funcs {
 	filter := func(object client.Object) bool {
-		return !IsReservedNamespace(object.GetName()) && object.GetAnnotations()[AnnotationServiceMesh] != ""
+		return isMeshAware(object, reserved)
 	}
 
 	return predicate.Funcs{
@@ -23,6 +23,11 @@ func MeshAwareNamespaces() predicate.Funcs {
 				return true
 			}
 
+			if !reserved.IsReserved(updateEvent.ObjectOld) && reserved.IsReserved(updateEvent.ObjectNew) {
+				// namespace has been just reserved, handle it in reconcile
+				return true
+			}
+
 			return filter(updateEvent.ObjectNew)
 		},
 		DeleteFunc: func(deleteEvent event.DeleteEvent) bool {
@@ -34,6 +39,23 @@ func MeshAwareNamespaces() predicate.Funcs {
 	}
 }
 
+// isMeshAware tells if the namespace is handled by the controller. Namespace holding the finalizer is always handled,
+// even if it has been reserved since, so that its mesh resources are torn down and the finalizer is released.
+func isMeshAware(object client.Object, reserved *ReservedNamespaces) bool {
+	if controllerutil.ContainsFinalizer(object, FinalizerServiceMesh) {
+		return true
+	}
+
+	return !reserved.IsReserved(object) && object.GetAnnotations()[AnnotationServiceMesh] != ""
+}
+
+// ObjectNamed filters events down to the single object of the given name.
+func ObjectNamed(key types.NamespacedName) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetName() == key.Name && object.GetNamespace() == key.Namespace
+	})
+}
+
 func annotationRemoved(e event.UpdateEvent, annotation string) bool {
 	_, existsInOld := e.ObjectOld.GetAnnotations()[annotation]
 	_, existsInNew := e.ObjectNew.GetAnnotations()[annotation]
@@ -41,8 +63,7 @@ func annotationRemoved(e event.UpdateEvent, annotation string) bool {
 	return existsInOld && !existsInNew
 }
 
-var reservedNamespaceRegex = regexp.MustCompile(`^(openshift|istio-system)$|^(kube|openshift)-.*$`)
-
+// IsReservedNamespace checks the namespace name against DefaultReservedNamespacePatterns.
 func IsReservedNamespace(namepace string) bool {
-	return reservedNamespaceRegex.MatchString(namepace)
+	return DefaultReservedNamespaces().isReservedName(namepace)
 }
@@ -1,6 +1,8 @@
 package controllers_test
 
 import (
+	"time"
+
 	"github.com/opendatahub-io/odh-project-controller/controllers"
 	"github.com/opendatahub-io/odh-project-controller/test/labels"
 	corev1 "k8s.io/api/core/v1"
@@ -17,7 +19,7 @@ var _ = Describe("Controller predicates functions", Label(labels.Unit), func() {
 
 		It("should trigger update event when annotation has been removed", func() {
 			// given
-			meshAwareNamespaces := controllers.MeshAwareNamespaces()
+			meshAwareNamespaces := controllers.MeshAwareNamespaces(controllers.DefaultReservedNamespaces())
 
 			// when
 			namespace := corev1.Namespace{
@@ -59,6 +61,104 @@ var _ = Describe("Controller predicates functions", Label(labels.Unit), func() {
 			Entry("istio-system-openshift is not reserved namespace", "istio-system-openshift ", false),
 		)
 
+		It("should trigger update event when namespace has been marked as reserved", func() {
+			// given
+			meshAwareNamespaces := controllers.MeshAwareNamespaces(controllers.DefaultReservedNamespaces())
+
+			// when
+			namespace := corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "ns-just-reserved",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh: "true",
+					},
+				},
+			}
+			reservedNamespace := corev1.Namespace{}
+			namespace.DeepCopyInto(&reservedNamespace)
+			reservedNamespace.SetLabels(map[string]string{
+				controllers.LabelReservedNamespace: "true",
+			})
+
+			namespaceReservedEvent := event.UpdateEvent{
+				ObjectOld: &namespace,
+				ObjectNew: &reservedNamespace,
+			}
+
+			// then
+			Expect(meshAwareNamespaces.UpdateFunc(namespaceReservedEvent)).To(BeTrue())
+		})
+
+		It("should trigger events of reserved namespace still holding the finalizer", func() {
+			// given
+			meshAwareNamespaces := controllers.MeshAwareNamespaces(controllers.DefaultReservedNamespaces())
+
+			// when
+			namespace := corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "ns-reserved-with-finalizer",
+					Labels:     map[string]string{controllers.LabelReservedNamespace: "true"},
+					Finalizers: []string{controllers.FinalizerServiceMesh},
+				},
+			}
+			deletedNamespace := corev1.Namespace{}
+			namespace.DeepCopyInto(&deletedNamespace)
+			deletedNamespace.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+			// then
+			Expect(meshAwareNamespaces.CreateFunc(event.CreateEvent{Object: &namespace})).To(BeTrue())
+			Expect(meshAwareNamespaces.UpdateFunc(event.UpdateEvent{ObjectOld: &namespace, ObjectNew: &deletedNamespace})).To(BeTrue())
+		})
+
+	})
+
+	When("Checking namespace against configured rules", func() {
+
+		var reserved *controllers.ReservedNamespaces
+
+		BeforeEach(func() {
+			var err error
+			reserved, err = controllers.NewReservedNamespaces([]string{"kube-.*", "openshift-.*"}, []string{"openshift-ai-.*"}, "example.com/reserved")
+			Expect(err).ToNot(HaveOccurred())
+			reserved.MeshNamespace = func() string {
+				return "my-mesh-system"
+			}
+		})
+
+		namespaceOf := func(name string, labels map[string]string) *corev1.Namespace {
+			return &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: labels,
+				},
+			}
+		}
+
+		It("should reserve namespace matching included pattern", func() {
+			Expect(reserved.IsReserved(namespaceOf("openshift-monitoring", nil))).To(BeTrue())
+		})
+
+		It("should not reserve namespace matching excluded pattern", func() {
+			Expect(reserved.IsReserved(namespaceOf("openshift-ai-projects", nil))).To(BeFalse())
+		})
+
+		It("should reserve namespace marked with the label", func() {
+			Expect(reserved.IsReserved(namespaceOf("mynamespace", map[string]string{"example.com/reserved": "true"}))).To(BeTrue())
+		})
+
+		It("should always reserve mesh namespace", func() {
+			Expect(reserved.IsReserved(namespaceOf("my-mesh-system", nil))).To(BeTrue())
+		})
+
+		It("should match patterns against the whole name", func() {
+			Expect(reserved.IsReserved(namespaceOf("my-kube-project", nil))).To(BeFalse())
+		})
+
+		It("should reject invalid pattern", func() {
+			_, err := controllers.NewReservedNamespaces([]string{"kube-("}, nil, "")
+			Expect(err).To(HaveOccurred())
+		})
+
 	})
 
 })
@@ -2,17 +2,32 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
 	"github.com/go-logr/logr"
+	meshv1alpha1 "github.com/opendatahub-io/odh-project-controller/api/v1alpha1"
+	routev1 "github.com/openshift/api/route/v1"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	v1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	k8serrs "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
 	maistrav1 "maistra.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // OpenshiftServiceMeshReconciler holds the controller configuration.
@@ -20,6 +35,25 @@ type OpenshiftServiceMeshReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Log    logr.Logger
+	Config *MeshConfigStore
+	// Reserved decides which namespaces are never enrolled in the mesh. DefaultReservedNamespaces are used when not set.
+	Reserved *ReservedNamespaces
+	// Recorder emits Events on the reconciled namespaces. Manager's recorder is used when not set.
+	Recorder record.EventRecorder
+	// Capabilities tells which of the optional APIs are served by the cluster. All of them are assumed available when not set.
+	Capabilities *Capabilities
+	// Features reconciled for every mesh-aware namespace. BuiltinFeatures are used when not set.
+	Features *FeatureRegistry
+	// DryRun makes the controller report changes to ServiceMeshMembers, gateway annotations and templated resources instead of applying them.
+	// Namespaces being deleted are still released, so that they are not held by the finalizer.
+	DryRun bool
+
+	watching        map[string]bool
+	templateKinds   map[schema.GroupVersionKind]bool
+	templateKindsMu sync.Mutex
+	controller      controller.Controller
+	cache           cache.Cache
+	requeue         chan event.GenericEvent
 }
 
 // +kubebuilder:rbac:groups=maistra.io,resources=servicemeshmembers;servicemeshmembers/finalizers,verbs=get;list;watch;create;update;patch;delete
@@ -27,14 +61,65 @@ type OpenshiftServiceMeshReconciler struct {
 // +kubebuilder:rbac:groups=maistra.io,resources=servicemeshcontrolplanes,verbs=get;list;watch;create;update;patch;use
 // +kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=namespaces/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=service-mesh.opendatahub.io,resources=projectmeshpolicies,verbs=get;list;watch
+// +kubebuilder:rbac:groups=service-mesh.opendatahub.io,resources=projectmeshstatuses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=service-mesh.opendatahub.io,resources=projectmeshstatuses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=networking.istio.io,resources=sidecars,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=security.istio.io,resources=peerauthentications;authorizationpolicies,verbs=get;list;watch;create;update;delete
+
+const (
+	FeatureGatewayAnnotations = "gateway-annotations"
+	FeatureMesh               = "mesh"
+	FeatureTemplates          = "templates"
+)
+
+// BuiltinFeatures returns features provided by the controller, to be registered in the FeatureRegistry.
+func (r *OpenshiftServiceMeshReconciler) BuiltinFeatures() []Feature {
+	var templateWatches []FeatureWatch
+	if r.Config != nil && r.Config.Source().Namespace != "" {
+		templateWatches = append(templateWatches, FeatureWatch{
+			Object: &v1.ConfigMap{}, Handler: handler.EnqueueRequestsFromMapFunc(r.templatesChanged),
+		})
+	}
 
-type reconcileFunc func(ctx context.Context, namespace *v1.Namespace) error
+	return []Feature{
+		{
+			Name:     FeatureGatewayAnnotations,
+			Requires: []Capability{RoutesCapability()},
+			Watches: []FeatureWatch{
+				{Object: &routev1.Route{}, Handler: handler.EnqueueRequestsFromMapFunc(r.gatewayRouteChanged)},
+			},
+			Reconcile: r.addGatewayAnnotations,
+			Cleanup:   r.removeGatewayAnnotations,
+		},
+		{
+			Name:     FeatureMesh,
+			Requires: []Capability{ServiceMeshCapability()},
+			Watches: []FeatureWatch{
+				{Object: &maistrav1.ServiceMeshMember{}, Handler: handler.EnqueueRequestsFromMapFunc(ServiceMeshMemberToNamespace)},
+				{Object: &maistrav1.ServiceMeshControlPlane{}, Handler: handler.EnqueueRequestsFromMapFunc(r.controlPlaneChanged)},
+			},
+			Reconcile: r.reconcileMeshMember,
+			Cleanup:   r.removeMeshMember,
+		},
+		{
+			Name:      FeatureTemplates,
+			DependsOn: []string{FeatureMesh},
+			Watches:   templateWatches,
+			Reconcile: r.applyTemplates,
+			Cleanup:   r.removeTemplateResources,
+		},
+	}
+}
 
 // Reconcile ensures that the namespace has all required resources needed to be part of the Service Mesh of Open Data Hub.
 func (r *OpenshiftServiceMeshReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	log := r.Log.WithValues("name", req.Name, "namespace", req.Namespace)
 
-	reconcilers := []reconcileFunc{r.addGatewayAnnotations, r.reconcileMeshMember}
+	features := r.Features.Features()
 
 	namespace := &v1.Namespace{}
 	if err := r.Get(ctx, req.NamespacedName, namespace); err != nil {
@@ -47,24 +132,280 @@ func (r *OpenshiftServiceMeshReconciler) Reconcile(ctx context.Context, req ctrl
 		return ctrl.Result{}, errors.Wrap(err, "failed getting namespace")
 	}
 
-	if serviceMeshIsNotEnabled(namespace.ObjectMeta) {
-		//nolint:godox //reason https://github.com/maistra/odh-project-controller/issues/65
-		// TODO handle clean-up here
-		return ctrl.Result{}, nil
+	if !namespace.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(namespace, FinalizerServiceMesh) {
+			return ctrl.Result{}, nil
+		}
+
+		log.Info("Namespace is being deleted, tearing down mesh resources")
+
+		return ctrl.Result{}, r.tearDown(ctx, namespace, features)
+	}
+
+	if serviceMeshIsNotEnabled(namespace.ObjectMeta) || r.Reserved.IsReserved(namespace) {
+		if r.DryRun {
+			log.Info("Dry run, skipping clean-up of namespace which opted out of the mesh")
+
+			return ctrl.Result{}, nil
+		}
+
+		// Namespace opted out of the mesh, remove everything we created for it
+		return ctrl.Result{}, r.tearDown(ctx, namespace, features)
+	}
+
+	if !r.DryRun {
+		if err := r.ensureFinalizer(ctx, namespace); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	policy, err := r.resolvePolicy(ctx, namespace)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
 	var errs []error
-	for _, reconciler := range reconcilers {
-		errs = append(errs, reconciler(ctx, namespace))
+
+	pending := false
+	results := make([]meshv1alpha1.FeatureStatus, 0, len(features))
+	succeeded := map[string]bool{}
+	disabled := map[string]bool{}
+
+	for _, f := range features {
+		if required := f.requiredCapabilities(); !r.Capabilities.Has(required...) {
+			results = append(results, meshv1alpha1.FeatureStatus{
+				Name:    f.Name,
+				State:   meshv1alpha1.FeatureUnavailable,
+				Message: fmt.Sprintf("requires %v APIs which are not served by the cluster", required),
+			})
+
+			continue
+		}
+
+		// Feature is switched off together with any of its dependencies
+		if !r.Features.IsEnabled(f.Name) || !isFeatureEnabled(policy, f.Name) || len(dependenciesIn(f, disabled)) > 0 {
+			disabled[f.Name] = true
+
+			if r.DryRun {
+				log.Info("Dry run, skipping clean-up of disabled feature", "feature", f.Name)
+				results = append(results, newFeatureStatus(f.Name, meshv1alpha1.FeatureDisabled, nil))
+
+				continue
+			}
+
+			// Feature could have been enabled before, ensure its leftovers are removed
+			err := observeFeature(f.Name, func() error { return f.Cleanup(ctx, namespace) })
+			errs = append(errs, err)
+			results = append(results, newFeatureStatus(f.Name, meshv1alpha1.FeatureDisabled, err))
+
+			continue
+		}
+
+		if blockedBy := unmetDependencies(f, succeeded); len(blockedBy) > 0 {
+			log.Info("Feature blocked by its dependencies", "feature", f.Name, "dependencies", blockedBy)
+
+			results = append(results, meshv1alpha1.FeatureStatus{
+				Name:    f.Name,
+				State:   meshv1alpha1.FeatureBlocked,
+				Message: fmt.Sprintf("waiting for %v to succeed", blockedBy),
+			})
+
+			continue
+		}
+
+		err := observeFeature(f.Name, func() error { return f.Reconcile(ctx, namespace) })
+		results = append(results, newFeatureStatus(f.Name, meshv1alpha1.FeatureSucceeded, err))
+		succeeded[f.Name] = err == nil
+
+		if isPending(err) {
+			r.Recorder.Event(namespace, v1.EventTypeNormal, ReasonWaitingForControlPlane, err.Error())
+
+			pending = true
+
+			continue
+		}
+
+		errs = append(errs, err)
+	}
+
+	if r.DryRun {
+		log.Info("Dry run, skipping mesh status update", "features", results)
+	} else if err := r.updateMeshStatus(ctx, namespace, results, k8serrs.NewAggregate(errs)); err != nil {
+		log.Error(err, "Unable to report mesh status")
+		errs = append(errs, err)
+	}
+
+	if err := k8serrs.NewAggregate(errs); err != nil {
+		r.Recorder.Event(namespace, v1.EventTypeWarning, ReasonReconcileFailed, err.Error())
+
+		return ctrl.Result{}, err
+	}
+
+	// Requeue with backoff, in case control plane becomes Ready without notice
+	return ctrl.Result{Requeue: pending}, nil
+}
+
+// unmetDependencies lists dependencies of the feature which have not succeeded in the current reconciliation.
+func unmetDependencies(f Feature, succeeded map[string]bool) []string {
+	var unmet []string
+
+	for _, dependency := range f.DependsOn {
+		if !succeeded[dependency] {
+			unmet = append(unmet, dependency)
+		}
 	}
 
-	return ctrl.Result{}, k8serrs.NewAggregate(errs)
+	return unmet
+}
+
+// dependenciesIn lists dependencies of the feature which are in the given set.
+func dependenciesIn(f Feature, features map[string]bool) []string {
+	var found []string
+
+	for _, dependency := range f.DependsOn {
+		if features[dependency] {
+			found = append(found, dependency)
+		}
+	}
+
+	return found
+}
+
+// tearDown runs clean-up of all features in the reverse order of their reconciliation.
+// Finalizer is released only when every feature has been successfully cleaned up.
+func (r *OpenshiftServiceMeshReconciler) tearDown(ctx context.Context, namespace *v1.Namespace, features []Feature) error {
+	log := r.Log.WithValues("namespace", namespace.Name)
+
+	var errs []error
+
+	var cleanedUp []string
+
+	for i := len(features) - 1; i >= 0; i-- {
+		if !r.Capabilities.Has(features[i].requiredCapabilities()...) {
+			// Without the API in place there is nothing the feature could have created
+			continue
+		}
+
+		if err := features[i].Cleanup(ctx, namespace); err != nil {
+			log.Error(err, "Unable to clean up feature", "feature", features[i].Name)
+			errs = append(errs, errors.Wrapf(err, "failed cleaning up %s", features[i].Name))
+
+			continue
+		}
+
+		cleanedUp = append(cleanedUp, features[i].Name)
+	}
+
+	if len(errs) > 0 {
+		log.Info("Mesh clean-up incomplete", "cleaned-up", cleanedUp, "failed", len(errs))
+
+		err := k8serrs.NewAggregate(errs)
+		r.Recorder.Event(namespace, v1.EventTypeWarning, ReasonCleanupFailed, err.Error())
+
+		return err
+	}
+
+	log.Info("Mesh clean-up completed", "cleaned-up", cleanedUp)
+
+	if err := r.removeMeshStatus(ctx, namespace); err != nil {
+		return err
+	}
+
+	return r.removeFinalizer(ctx, namespace)
+}
+
+func (r *OpenshiftServiceMeshReconciler) ensureFinalizer(ctx context.Context, namespace *v1.Namespace) error {
+	if !controllerutil.AddFinalizer(namespace, FinalizerServiceMesh) {
+		return nil
+	}
+
+	return errors.Wrap(r.Update(ctx, namespace), "failed adding finalizer to namespace")
+}
+
+func (r *OpenshiftServiceMeshReconciler) removeFinalizer(ctx context.Context, namespace *v1.Namespace) error {
+	if !controllerutil.RemoveFinalizer(namespace, FinalizerServiceMesh) {
+		return nil
+	}
+
+	return errors.Wrap(client.IgnoreNotFound(r.Update(ctx, namespace)), "failed removing finalizer from namespace")
 }
 
 func (r *OpenshiftServiceMeshReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	//nolint:wrapcheck //reason there is no point in wrapping it
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1.Namespace{}, builder.WithPredicates(MeshAwareNamespaces())).
-		Owns(&maistrav1.ServiceMeshMember{}).
-		Complete(r)
+	if r.Reserved == nil {
+		r.Reserved = DefaultReservedNamespaces()
+	}
+
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor(ManagedByValue)
+	}
+
+	if r.Reserved.MeshNamespace == nil {
+		r.Reserved.MeshNamespace = func() string {
+			return r.Config.Get().MeshNamespace
+		}
+	}
+
+	if r.Capabilities == nil {
+		r.Capabilities = AllCapabilities()
+	}
+
+	if r.Features == nil {
+		r.Features = NewFeatureRegistry()
+		if err := r.Features.Register(r.BuiltinFeatures()...); err != nil {
+			return errors.Wrap(err, "failed registering built-in features")
+		}
+	}
+
+	if err := r.Features.Validate(); err != nil {
+		return errors.Wrap(err, "invalid features configuration")
+	}
+
+	if err := metrics.Registry.Register(NewEnrolledNamespacesCollector(mgr.GetClient())); err != nil {
+		if !errors.As(err, &prometheus.AlreadyRegisteredError{}) {
+			return errors.Wrap(err, "failed registering enrolled namespaces metric")
+		}
+	}
+
+	r.cache = mgr.GetCache()
+	r.requeue = make(chan event.GenericEvent)
+
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&v1.Namespace{}, builder.WithPredicates(MeshAwareNamespaces(r.Reserved))).
+		Watches(&meshv1alpha1.ProjectMeshPolicy{}, handler.EnqueueRequestsFromMapFunc(r.meshPolicyChanged)).
+		WatchesRawSource(&source.Channel{Source: r.requeue}, &handler.EnqueueRequestForObject{})
+
+	r.watching = map[string]bool{}
+
+	for _, f := range r.Features.Features() {
+		r.Capabilities.Track(f.Requires...)
+
+		if !r.Capabilities.Has(f.requiredCapabilities()...) {
+			// Watches are started once the APIs are discovered
+			continue
+		}
+
+		for _, w := range f.Watches {
+			controllerBuilder = controllerBuilder.Watches(w.Object, w.Handler)
+		}
+
+		r.watching[f.Name] = true
+	}
+
+	if source := r.Config.Source(); source.Name != "" && source.Namespace != "" {
+		controllerBuilder = controllerBuilder.
+			Watches(&v1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.meshConfigChanged), builder.WithPredicates(ObjectNamed(source)))
+	}
+
+	var err error
+	if r.controller, err = controllerBuilder.Build(r); err != nil {
+		return errors.Wrap(err, "failed building controller")
+	}
+
+	if missing := r.Capabilities.Missing(); len(missing) > 0 {
+		r.Log.Info("Features depending on missing APIs are disabled until the APIs are served", "missing", missing)
+
+		return errors.Wrap(mgr.Add(manager.RunnableFunc(r.discoverCapabilities)), "failed adding capability discovery")
+	}
+
+	return nil
 }
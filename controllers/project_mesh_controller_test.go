@@ -3,13 +3,14 @@ package controllers_test
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
+	meshv1alpha1 "github.com/opendatahub-io/odh-project-controller/api/v1alpha1"
 	"github.com/opendatahub-io/odh-project-controller/controllers"
 	"github.com/opendatahub-io/odh-project-controller/test/labels"
 	openshiftv1 "github.com/openshift/api/route/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	maistrav1 "maistra.io/api/core/v1"
@@ -32,6 +33,7 @@ var _ = When("Namespace is created", Label(labels.EnvTest), func() {
 		testNs *corev1.Namespace
 		objectCleaner *Cleaner
 		route         *openshiftv1.Route
+		basicSmcp     *maistrav1.ServiceMeshControlPlane
 	)
 
 	BeforeEach(func() {
@@ -64,10 +66,11 @@ var _ = When("Namespace is created", Label(labels.EnvTest), func() {
 
 		Expect(cli.Create(context.Background(), istioNs)).To(Succeed())
 		Expect(cli.Create(context.Background(), route)).To(Succeed())
+		basicSmcp = createReadyControlPlane(istioNs.Name, "basic")
 	})
 
 	AfterEach(func() {
-		objectCleaner.DeleteAll(istioNs, route, testNs)
+		objectCleaner.DeleteAll(istioNs, route, basicSmcp, testNs)
 	})
 
 	Context("enabling service mesh", func() {
@@ -100,6 +103,27 @@ var _ = When("Namespace is created", Label(labels.EnvTest), func() {
 					WithPolling(interval).
 					Should(Succeed())
 			})
+
+			By("emitting an event on the namespace", func() {
+				Eventually(func() []string {
+					return eventReasonsFor(testNs)
+				}).
+					WithTimeout(timeout).
+					WithPolling(interval).
+					Should(ContainElement(controllers.ReasonServiceMeshMemberCreated))
+			})
+
+			By("reporting pending membership on the namespace", func() {
+				actualTestNs := &corev1.Namespace{}
+				Eventually(func() string {
+					_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+					return actualTestNs.Annotations[controllers.AnnotationMemberStatus]
+				}).
+					WithTimeout(timeout).
+					WithPolling(interval).
+					Should(Equal(string(controllers.MemberPending)))
+			})
 		})
 
 		It("should not register it in the mesh if annotation is absent", func() {
@@ -197,7 +221,7 @@ var _ = When("Namespace is created", Label(labels.EnvTest), func() {
 			})
 		})
 
-		It("should create an SMM with specified name defined in env var", func() {
+		It("should create an SMM with specified name defined in config map", func() {
 			// given
 			testNs = &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
@@ -208,10 +232,21 @@ var _ = When("Namespace is created", Label(labels.EnvTest), func() {
 				},
 			}
 
-			_ = os.Setenv(controllers.ControlPlaneEnv, "minimal")
-			defer os.Unsetenv(controllers.ControlPlaneEnv)
-			_ = os.Setenv(controllers.MeshNamespaceEnv, "istio-system")
-			defer os.Unsetenv(controllers.MeshNamespaceEnv)
+			meshConfig := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      meshConfigSource.Name,
+					Namespace: meshConfigSource.Namespace,
+				},
+				Data: map[string]string{
+					controllers.ControlPlaneEnv:  "minimal",
+					controllers.MeshNamespaceEnv: "istio-system",
+				},
+			}
+			Expect(cli.Create(context.Background(), meshConfig)).To(Succeed())
+			defer objectCleaner.DeleteAll(meshConfig)
+
+			minimalSmcp := createReadyControlPlane("istio-system", "minimal")
+			defer objectCleaner.DeleteAll(minimalSmcp)
 
 			// when
 			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
@@ -223,57 +258,121 @@ var _ = When("Namespace is created", Label(labels.EnvTest), func() {
 					Namespace: testNs.Name,
 					Name:      "default",
 				}
-				Eventually(func() error {
-					return cli.Get(context.Background(), namespacedName, member)
+				Eventually(func() string {
+					_ = cli.Get(context.Background(), namespacedName, member)
+
+					return member.Spec.ControlPlaneRef.Name
 				}).
 					WithTimeout(timeout).
 					WithPolling(interval).
-					Should(Succeed())
-				Expect(member.Spec.ControlPlaneRef.Name).To(Equal("minimal"))
+					Should(Equal("minimal"))
 				Expect(member.Spec.ControlPlaneRef.Namespace).To(Equal("istio-system"))
 			})
 		})
-	})
-
-	Context("propagating service mesh gateway info", func() {
 
-		It("should add just gateway name to the namespace if there is no gateway namespace defined", func() {
+		It("should move existing SMM to the control plane defined in updated config map", func() {
 			// given
 			testNs = &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "plain-meshified-ns",
+					Name: "migrated-ns",
 					Annotations: map[string]string{
 						controllers.AnnotationServiceMesh: "true",
 					},
 				},
 			}
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
 
-			// update route to remove gateway namespace label
-			delete(route.Labels, controllers.LabelMaistraGatewayNamespace)
-			Expect(cli.Update(context.Background(), route)).To(Succeed())
+			member := &maistrav1.ServiceMeshMember{}
+			namespacedName := types.NamespacedName{
+				Namespace: testNs.Name,
+				Name:      "default",
+			}
+			Eventually(func() error {
+				return cli.Get(context.Background(), namespacedName, member)
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Succeed())
+			Expect(member.Spec.ControlPlaneRef.Name).To(Equal("basic"))
+
+			regulatedSmcp := createReadyControlPlane("istio-system", "regulated")
+			defer objectCleaner.DeleteAll(regulatedSmcp)
 
 			// when
-			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+			meshConfig := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      meshConfigSource.Name,
+					Namespace: meshConfigSource.Namespace,
+				},
+				Data: map[string]string{
+					controllers.ControlPlaneEnv: "regulated",
+				},
+			}
+			Expect(cli.Create(context.Background(), meshConfig)).To(Succeed())
+			defer objectCleaner.DeleteAll(meshConfig)
 
 			// then
-			actualTestNs := &corev1.Namespace{}
 			Eventually(func() string {
-				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+				_ = cli.Get(context.Background(), namespacedName, member)
 
-				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayName]
+				return member.Spec.ControlPlaneRef.Name
 			}).
 				WithTimeout(timeout).
 				WithPolling(interval).
-				Should(Equal("odh-gateway"))
+				Should(Equal("regulated"))
 		})
+	})
 
-		It("should add fully qualified gateway name to the namespace", func() {
+	Context("selecting control plane", func() {
+
+		var (
+			regulatedMeshNs *corev1.Namespace
+			controlPlane    *maistrav1.ServiceMeshControlPlane
+			selectionPolicy *meshv1alpha1.ProjectMeshPolicy
+		)
+
+		BeforeEach(func() {
+			regulatedMeshNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "regulated-mesh",
+				},
+			}
+			Expect(cli.Create(context.Background(), regulatedMeshNs)).To(Succeed())
+			controlPlane = createReadyControlPlane(regulatedMeshNs.Name, "restricted")
+
+			selectionPolicy = &meshv1alpha1.ProjectMeshPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "control-plane-selection",
+				},
+				Spec: meshv1alpha1.ProjectMeshPolicySpec{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"control-plane-selection": "allowed"},
+					},
+					AllowedControlPlanes: []meshv1alpha1.ControlPlaneReference{
+						{Name: "restricted", Namespace: regulatedMeshNs.Name},
+						{Name: "non-existing", Namespace: regulatedMeshNs.Name},
+						{Name: "installing", Namespace: regulatedMeshNs.Name},
+					},
+				},
+			}
+			Expect(cli.Create(context.Background(), selectionPolicy)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			objectCleaner.DeleteAll(selectionPolicy, controlPlane, regulatedMeshNs)
+		})
+
+		It("should create an SMM referring to control plane selected through annotation", func() {
 			// given
 			testNs = &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "plain-meshified-ns",
+					Name: "regulated-ns",
+					Labels: map[string]string{
+						"control-plane-selection": "allowed",
+					},
 					Annotations: map[string]string{
-						controllers.AnnotationServiceMesh: "true",
+						controllers.AnnotationServiceMesh:  "true",
+						controllers.AnnotationControlPlane: "regulated-mesh/restricted",
 					},
 				},
 			}
@@ -282,22 +381,77 @@ var _ = When("Namespace is created", Label(labels.EnvTest), func() {
 			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
 
 			// then
-			actualTestNs := &corev1.Namespace{}
-			Eventually(func() string {
-				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+			member := &maistrav1.ServiceMeshMember{}
+			namespacedName := types.NamespacedName{
+				Namespace: testNs.Name,
+				Name:      "default",
+			}
+			Eventually(func() error {
+				return cli.Get(context.Background(), namespacedName, member)
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Succeed())
+			Expect(member.Spec.ControlPlaneRef.Name).To(Equal("restricted"))
+			Expect(member.Spec.ControlPlaneRef.Namespace).To(Equal("regulated-mesh"))
+		})
 
-				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayName]
+		It("should create an SMM referring to default control plane when selected one is not allowed", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "not-allowed-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh:  "true",
+						controllers.AnnotationControlPlane: "regulated-mesh/restricted",
+					},
+				},
+			}
+
+			// when
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			// then
+			member := &maistrav1.ServiceMeshMember{}
+			namespacedName := types.NamespacedName{
+				Namespace: testNs.Name,
+				Name:      "default",
+			}
+			Eventually(func() error {
+				return cli.Get(context.Background(), namespacedName, member)
 			}).
 				WithTimeout(timeout).
 				WithPolling(interval).
-				Should(Equal("opendatahub/odh-gateway"))
+				Should(Succeed())
+			Expect(member.Spec.ControlPlaneRef.Name).To(Equal("basic"))
+			Expect(member.Spec.ControlPlaneRef.Namespace).To(Equal("istio-system"))
 		})
 
-		It("should add external gateway host to the namespace", func() {
+		It("should create an SMM referring to control plane of matching mesh policy", func() {
 			// given
+			policy := &meshv1alpha1.ProjectMeshPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "regulated-projects",
+				},
+				Spec: meshv1alpha1.ProjectMeshPolicySpec{
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"tier": "regulated"},
+					},
+					ControlPlaneRef: &meshv1alpha1.ControlPlaneReference{
+						Name:      "restricted",
+						Namespace: "regulated-mesh",
+					},
+				},
+			}
+			Expect(cli.Create(context.Background(), policy)).To(Succeed())
+			defer objectCleaner.DeleteAll(policy)
+
 			testNs = &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "plain-meshified-ns",
+					Name: "regulated-by-policy-ns",
+					Labels: map[string]string{
+						"tier": "regulated",
+					},
 					Annotations: map[string]string{
 						controllers.AnnotationServiceMesh: "true",
 					},
@@ -308,22 +462,115 @@ var _ = When("Namespace is created", Label(labels.EnvTest), func() {
 			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
 
 			// then
-			actualTestNs := &corev1.Namespace{}
-			Eventually(func() string {
-				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+			member := &maistrav1.ServiceMeshMember{}
+			namespacedName := types.NamespacedName{
+				Namespace: testNs.Name,
+				Name:      "default",
+			}
+			Eventually(func() error {
+				return cli.Get(context.Background(), namespacedName, member)
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Succeed())
+			Expect(member.Spec.ControlPlaneRef.Name).To(Equal("restricted"))
+			Expect(member.Spec.ControlPlaneRef.Namespace).To(Equal("regulated-mesh"))
+		})
 
-				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayExternalHost]
+		It("should not create an SMM when selected control plane does not exist", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "misconfigured-ns",
+					Labels: map[string]string{
+						"control-plane-selection": "allowed",
+					},
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh:  "true",
+						controllers.AnnotationControlPlane: "regulated-mesh/non-existing",
+					},
+				},
+			}
+
+			// when
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			// then
+			members := &maistrav1.ServiceMeshMemberList{}
+			Consistently(func() bool {
+				if err := cli.List(context.Background(), members, client.InNamespace(testNs.Name)); err != nil {
+					fmt.Printf("failed ensuring no service mesh member created: %+v\n", err)
+
+					return false
+				}
+
+				return len(members.Items) == 0
 			}).
 				WithTimeout(timeout).
 				WithPolling(interval).
-				Should(Equal("istio.io"))
+				Should(BeTrue())
 		})
 
-		It("should add internal gateway host to the namespace", func() {
+		It("should hold enrolment until selected control plane becomes ready", func() {
+			// given
+			installingSmcp := &maistrav1.ServiceMeshControlPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "installing",
+					Namespace: regulatedMeshNs.Name,
+				},
+			}
+			Expect(cli.Create(context.Background(), installingSmcp)).To(Succeed())
+			defer objectCleaner.DeleteAll(installingSmcp)
+
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "waiting-ns",
+					Labels: map[string]string{
+						"control-plane-selection": "allowed",
+					},
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh:  "true",
+						controllers.AnnotationControlPlane: "regulated-mesh/installing",
+					},
+				},
+			}
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			meshStatus := &meshv1alpha1.ProjectMeshStatus{}
+			Eventually(func() meshv1alpha1.MeshPhase {
+				_ = cli.Get(context.Background(), types.NamespacedName{Namespace: testNs.Name, Name: "default"}, meshStatus)
+
+				return meshStatus.Status.Phase
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Equal(meshv1alpha1.MeshPhasePending))
+
+			members := &maistrav1.ServiceMeshMemberList{}
+			Expect(cli.List(context.Background(), members, client.InNamespace(testNs.Name))).To(Succeed())
+			Expect(members.Items).To(BeEmpty())
+
+			// when
+			markControlPlaneReady(installingSmcp)
+
+			// then
+			Eventually(func() error {
+				return cli.Get(context.Background(), types.NamespacedName{Namespace: testNs.Name, Name: "default"}, &maistrav1.ServiceMeshMember{})
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Succeed())
+		})
+
+	})
+
+	Context("reporting mesh status", func() {
+
+		It("should report pending enrolment until service mesh member is ready", func() {
 			// given
 			testNs = &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "plain-meshified-ns",
+					Name: "mesh-status-ns",
 					Annotations: map[string]string{
 						controllers.AnnotationServiceMesh: "true",
 					},
@@ -334,17 +581,844 @@ var _ = When("Namespace is created", Label(labels.EnvTest), func() {
 			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
 
 			// then
-			actualTestNs := &corev1.Namespace{}
-			Eventually(func() string {
-				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+			meshStatus := &meshv1alpha1.ProjectMeshStatus{}
+			Eventually(func() meshv1alpha1.MeshPhase {
+				_ = cli.Get(context.Background(), types.NamespacedName{Namespace: testNs.Name, Name: "default"}, meshStatus)
 
-				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayInternalHost]
+				return meshStatus.Status.Phase
 			}).
 				WithTimeout(timeout).
 				WithPolling(interval).
-				Should(Equal("istio-ingressgateway.istio-system.svc.cluster.local"))
+				Should(Equal(meshv1alpha1.MeshPhasePending))
+
+			Expect(meshStatus.Status.MemberReady).To(BeFalse())
+			Expect(meshStatus.Status.ControlPlane).To(Equal("istio-system/basic"))
+			Expect(meshStatus.Status.Features).To(ConsistOf(
+				meshv1alpha1.FeatureStatus{Name: controllers.FeatureGatewayAnnotations, State: meshv1alpha1.FeatureSucceeded},
+				meshv1alpha1.FeatureStatus{Name: controllers.FeatureMesh, State: meshv1alpha1.FeatureSucceeded},
+				meshv1alpha1.FeatureStatus{Name: controllers.FeatureTemplates, State: meshv1alpha1.FeatureSucceeded},
+			))
 		})
 
 	})
 
-})
+	Context("healing service mesh member", func() {
+
+		It("should recreate service mesh member owned by the namespace when deleted", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "healed-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh: "true",
+					},
+				},
+			}
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			member := &maistrav1.ServiceMeshMember{}
+			namespacedName := types.NamespacedName{
+				Namespace: testNs.Name,
+				Name:      "default",
+			}
+			Eventually(func() error {
+				return cli.Get(context.Background(), namespacedName, member)
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Succeed())
+			originalUID := member.UID
+
+			// when
+			Expect(cli.Delete(context.Background(), member)).To(Succeed())
+
+			// then
+			Eventually(func() types.UID {
+				recreated := &maistrav1.ServiceMeshMember{}
+				if err := cli.Get(context.Background(), namespacedName, recreated); err != nil {
+					return originalUID
+				}
+				member = recreated
+
+				return recreated.UID
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				ShouldNot(Equal(originalUID))
+			Expect(member.Labels).To(HaveKeyWithValue(controllers.LabelManagedBy, controllers.ManagedByValue))
+			Expect(metav1.GetControllerOf(member)).ToNot(BeNil())
+			Expect(metav1.GetControllerOf(member).Kind).To(Equal("Namespace"))
+			Expect(metav1.GetControllerOf(member).Name).To(Equal(testNs.Name))
+		})
+
+	})
+
+	Context("applying templated resources", func() {
+
+		It("should keep resources rendered from templates in sync with them", func() {
+			// given
+			templates := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      controllers.MeshTemplatesConfigMapName,
+					Namespace: meshConfigSource.Namespace,
+				},
+				Data: map[string]string{
+					"settings": `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: mesh-settings
+data:
+  namespace: {{ .Namespace.Name }}
+  control-plane: {{ .ControlPlane.Namespace }}/{{ .ControlPlane.Name }}
+`,
+				},
+			}
+			Expect(cli.Create(context.Background(), templates)).To(Succeed())
+			defer objectCleaner.DeleteAll(templates)
+
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "templated-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh: "true",
+					},
+				},
+			}
+
+			// when
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			// then
+			settings := &corev1.ConfigMap{}
+			namespacedName := types.NamespacedName{Namespace: testNs.Name, Name: "mesh-settings"}
+
+			By("creating resource rendered for the namespace", func() {
+				Eventually(func() map[string]string {
+					_ = cli.Get(context.Background(), namespacedName, settings)
+
+					return settings.Data
+				}).
+					WithTimeout(timeout).
+					WithPolling(interval).
+					Should(Equal(map[string]string{"namespace": "templated-ns", "control-plane": "istio-system/basic"}))
+				Expect(settings.Labels).To(HaveKeyWithValue(controllers.LabelTemplate, "settings"))
+			})
+
+			By("restoring resource modified out of band", func() {
+				settings.Data["control-plane"] = "istio-system/modified"
+				Expect(cli.Update(context.Background(), settings)).To(Succeed())
+
+				Eventually(func() string {
+					_ = cli.Get(context.Background(), namespacedName, settings)
+
+					return settings.Data["control-plane"]
+				}).
+					WithTimeout(timeout).
+					WithPolling(interval).
+					Should(Equal("istio-system/basic"))
+			})
+
+			By("recreating resource deleted out of band", func() {
+				originalUID := settings.UID
+				Expect(cli.Delete(context.Background(), settings)).To(Succeed())
+
+				Eventually(func() types.UID {
+					recreated := &corev1.ConfigMap{}
+					if err := cli.Get(context.Background(), namespacedName, recreated); err != nil {
+						return originalUID
+					}
+
+					return recreated.UID
+				}).
+					WithTimeout(timeout).
+					WithPolling(interval).
+					ShouldNot(Equal(originalUID))
+			})
+
+			By("removing resource when its template is gone", func() {
+				Expect(cli.Delete(context.Background(), templates)).To(Succeed())
+
+				Eventually(func() bool {
+					return apierrors.IsNotFound(cli.Get(context.Background(), namespacedName, settings))
+				}).
+					WithTimeout(timeout).
+					WithPolling(interval).
+					Should(BeTrue())
+			})
+		})
+
+	})
+
+	Context("disabling service mesh", func() {
+
+		It("should remove it from the mesh when annotation is set to false", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "opted-out-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh: "true",
+					},
+				},
+			}
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			member := &maistrav1.ServiceMeshMember{}
+			namespacedName := types.NamespacedName{
+				Namespace: testNs.Name,
+				Name:      "default",
+			}
+			Eventually(func() error {
+				return cli.Get(context.Background(), namespacedName, member)
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Succeed())
+
+			// when
+			Eventually(func() error {
+				actualTestNs := &corev1.Namespace{}
+				if err := cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs); err != nil {
+					return err
+				}
+				actualTestNs.Annotations[controllers.AnnotationServiceMesh] = "false"
+
+				return cli.Update(context.Background(), actualTestNs)
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Succeed())
+
+			// then
+			By("deleting service mesh member object in the namespace", func() {
+				Eventually(func() bool {
+					return apierrors.IsNotFound(cli.Get(context.Background(), namespacedName, member))
+				}).
+					WithTimeout(timeout).
+					WithPolling(interval).
+					Should(BeTrue())
+			})
+
+			By("removing gateway annotations from the namespace", func() {
+				actualTestNs := &corev1.Namespace{}
+				Eventually(func() map[string]string {
+					_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+					return actualTestNs.Annotations
+				}).
+					WithTimeout(timeout).
+					WithPolling(interval).
+					Should(SatisfyAll(
+						Not(HaveKey(controllers.AnnotationPublicGatewayName)),
+						Not(HaveKey(controllers.AnnotationPublicGatewayExternalHost)),
+						Not(HaveKey(controllers.AnnotationPublicGatewayInternalHost)),
+					))
+			})
+		})
+
+	})
+
+	Context("opting out with gateway annotations set by the user", func() {
+
+		It("should only remove gateway annotations set by the controller", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "user-gateway-opted-out-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh:               "true",
+						controllers.AnnotationPublicGatewayExternalHost: "my.gateway.io",
+					},
+				},
+			}
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			actualTestNs := &corev1.Namespace{}
+			Eventually(func() string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayInternalHost]
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Equal("istio-ingressgateway.istio-system.svc.cluster.local"))
+
+			// when
+			Eventually(func() error {
+				if err := cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs); err != nil {
+					return err
+				}
+				actualTestNs.Annotations[controllers.AnnotationServiceMesh] = "false"
+				actualTestNs.Annotations[controllers.AnnotationPublicGatewayName] = "my-gateways/my-gateway"
+
+				return cli.Update(context.Background(), actualTestNs)
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Succeed())
+
+			// then
+			Eventually(func() map[string]string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(SatisfyAll(
+					Not(HaveKey(controllers.AnnotationPublicGatewayInternalHost)),
+					Not(HaveKey(controllers.AnnotationPublicGatewayManaged)),
+					HaveKeyWithValue(controllers.AnnotationPublicGatewayExternalHost, "my.gateway.io"),
+					HaveKeyWithValue(controllers.AnnotationPublicGatewayName, "my-gateways/my-gateway"),
+				))
+		})
+
+	})
+
+	Context("opting out namespace annotated before the upgrade", func() {
+
+		It("should remove gateway annotations matching the route even though they are not recorded", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "upgraded-opted-out-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh:               "false",
+						controllers.AnnotationPublicGatewayName:         "opendatahub/odh-gateway",
+						controllers.AnnotationPublicGatewayExternalHost: "istio.io",
+						controllers.AnnotationPublicGatewayInternalHost: "my-gateway.my-gateways.svc.cluster.local",
+					},
+				},
+			}
+
+			// when
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			// then
+			actualTestNs := &corev1.Namespace{}
+			Eventually(func() map[string]string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(SatisfyAll(
+					Not(HaveKey(controllers.AnnotationPublicGatewayName)),
+					Not(HaveKey(controllers.AnnotationPublicGatewayExternalHost)),
+					HaveKeyWithValue(controllers.AnnotationPublicGatewayInternalHost, "my-gateway.my-gateways.svc.cluster.local"),
+				))
+		})
+
+	})
+
+	Context("deleting mesh-enabled namespace", func() {
+
+		It("should tear down mesh resources before the namespace goes away", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "deleted-meshified-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh: "true",
+					},
+				},
+			}
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			actualTestNs := &corev1.Namespace{}
+			Eventually(func() []string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Finalizers
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(ContainElement(controllers.FinalizerServiceMesh))
+
+			member := &maistrav1.ServiceMeshMember{}
+			namespacedName := types.NamespacedName{
+				Namespace: testNs.Name,
+				Name:      "default",
+			}
+			Eventually(func() error {
+				return cli.Get(context.Background(), namespacedName, member)
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Succeed())
+
+			// when
+			Expect(cli.Delete(context.Background(), testNs)).To(Succeed())
+
+			// then
+			By("deleting service mesh member object in the namespace", func() {
+				Eventually(func() bool {
+					return apierrors.IsNotFound(cli.Get(context.Background(), namespacedName, member))
+				}).
+					WithTimeout(timeout).
+					WithPolling(interval).
+					Should(BeTrue())
+			})
+
+			By("releasing the finalizer", func() {
+				Eventually(func() []string {
+					if err := cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs); err != nil {
+						return nil
+					}
+
+					return actualTestNs.Finalizers
+				}).
+					WithTimeout(timeout).
+					WithPolling(interval).
+					ShouldNot(ContainElement(controllers.FinalizerServiceMesh))
+			})
+		})
+
+	})
+
+	Context("deleting reserved namespace", func() {
+
+		It("should release the finalizer of namespace reserved after it has been enrolled", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "reserved-enrolled-ns",
+					Labels: map[string]string{
+						controllers.LabelReservedNamespace: "true",
+					},
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh: "true",
+					},
+					Finalizers: []string{controllers.FinalizerServiceMesh},
+				},
+			}
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			// when
+			Expect(cli.Delete(context.Background(), testNs)).To(Succeed())
+
+			// then
+			actualTestNs := &corev1.Namespace{}
+			Eventually(func() []string {
+				if err := cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs); err != nil {
+					return nil
+				}
+
+				return actualTestNs.Finalizers
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				ShouldNot(ContainElement(controllers.FinalizerServiceMesh))
+		})
+
+	})
+
+	Context("propagating service mesh gateway info", func() {
+
+		It("should not add gateway annotations when the feature is disabled by mesh policy", func() {
+			// given
+			policy := &meshv1alpha1.ProjectMeshPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mesh-only",
+				},
+				Spec: meshv1alpha1.ProjectMeshPolicySpec{
+					Features: []string{controllers.FeatureMesh},
+				},
+			}
+			Expect(cli.Create(context.Background(), policy)).To(Succeed())
+			defer objectCleaner.DeleteAll(policy)
+
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mesh-only-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh: "true",
+					},
+				},
+			}
+
+			// when
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			// then
+			Eventually(func() error {
+				return cli.Get(context.Background(), types.NamespacedName{Namespace: testNs.Name, Name: "default"}, &maistrav1.ServiceMeshMember{})
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Succeed())
+
+			actualTestNs := &corev1.Namespace{}
+			Consistently(func() map[string]string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				ShouldNot(HaveKey(controllers.AnnotationPublicGatewayName))
+		})
+
+		It("should add just gateway name to the namespace if there is no gateway namespace defined", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "plain-meshified-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh: "true",
+					},
+				},
+			}
+
+			// update route to remove gateway namespace label
+			delete(route.Labels, controllers.LabelMaistraGatewayNamespace)
+			Expect(cli.Update(context.Background(), route)).To(Succeed())
+
+			// when
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			// then
+			actualTestNs := &corev1.Namespace{}
+			Eventually(func() string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayName]
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Equal("odh-gateway"))
+		})
+
+		It("should add fully qualified gateway name to the namespace", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "plain-meshified-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh: "true",
+					},
+				},
+			}
+
+			// when
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			// then
+			actualTestNs := &corev1.Namespace{}
+			Eventually(func() string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayName]
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Equal("opendatahub/odh-gateway"))
+		})
+
+		It("should add external gateway host to the namespace", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "plain-meshified-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh: "true",
+					},
+				},
+			}
+
+			// when
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			// then
+			actualTestNs := &corev1.Namespace{}
+			Eventually(func() string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayExternalHost]
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Equal("istio.io"))
+		})
+
+		It("should use the route matching selector defined in config map", func() {
+			// given
+			weight := int32(100)
+			customRoute := &openshiftv1.Route{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "custom-gateway",
+					Namespace: "istio-system",
+					Labels: map[string]string{
+						"istio": "ingressgateway",
+					},
+				},
+				Spec: openshiftv1.RouteSpec{
+					Host: "custom.istio.io",
+					To: openshiftv1.RouteTargetReference{
+						Name:   "custom-ingressgateway",
+						Weight: &weight,
+					},
+				},
+			}
+			Expect(cli.Create(context.Background(), customRoute)).To(Succeed())
+			defer objectCleaner.DeleteAll(customRoute)
+
+			meshConfig := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      meshConfigSource.Name,
+					Namespace: meshConfigSource.Namespace,
+				},
+				Data: map[string]string{
+					controllers.GatewayRouteSelectorEnv: "istio=ingressgateway",
+				},
+			}
+			Expect(cli.Create(context.Background(), meshConfig)).To(Succeed())
+			defer objectCleaner.DeleteAll(meshConfig)
+
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "custom-gateway-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh: "true",
+					},
+				},
+			}
+
+			// when
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			// then
+			actualTestNs := &corev1.Namespace{}
+			Eventually(func() string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayExternalHost]
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Equal("custom.istio.io"))
+			Expect(actualTestNs.Annotations[controllers.AnnotationPublicGatewayInternalHost]).To(Equal("custom-ingressgateway.istio-system.svc.cluster.local"))
+		})
+
+		It("should update gateway annotations when the route changes", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "route-sync-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh: "true",
+					},
+				},
+			}
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			actualTestNs := &corev1.Namespace{}
+			Eventually(func() string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayExternalHost]
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Equal("istio.io"))
+
+			// when
+			route.Spec.Host = "https://gateway.istio.io/"
+			Expect(cli.Update(context.Background(), route)).To(Succeed())
+
+			// then
+			Eventually(func() string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayExternalHost]
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Equal("gateway.istio.io"))
+		})
+
+		It("should update gateway annotations set before they were recorded when the route changes", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "upgraded-route-sync-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh:               "true",
+						controllers.AnnotationPublicGatewayName:         "opendatahub/odh-gateway",
+						controllers.AnnotationPublicGatewayExternalHost: "istio.io",
+						controllers.AnnotationPublicGatewayInternalHost: "istio-ingressgateway.istio-system.svc.cluster.local",
+					},
+				},
+			}
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			actualTestNs := &corev1.Namespace{}
+			Eventually(func() map[string]string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(HaveKey(controllers.AnnotationPublicGatewayManaged))
+
+			// when
+			route.Spec.Host = "https://gateway.istio.io/"
+			Expect(cli.Update(context.Background(), route)).To(Succeed())
+
+			// then
+			Eventually(func() string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayExternalHost]
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Equal("gateway.istio.io"))
+		})
+
+		It("should keep gateway annotation set by the user when the route changes", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "user-gateway-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh:               "true",
+						controllers.AnnotationPublicGatewayExternalHost: "my.gateway.io",
+					},
+				},
+			}
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			actualTestNs := &corev1.Namespace{}
+			Eventually(func() string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayInternalHost]
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Equal("istio-ingressgateway.istio-system.svc.cluster.local"))
+
+			// when
+			route.Spec.To.Name = "other-ingressgateway"
+			Expect(cli.Update(context.Background(), route)).To(Succeed())
+
+			// then
+			Eventually(func() string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayInternalHost]
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Equal("other-ingressgateway.istio-system.svc.cluster.local"))
+			Expect(actualTestNs.Annotations[controllers.AnnotationPublicGatewayExternalHost]).To(Equal("my.gateway.io"))
+		})
+
+		It("should leave gateway annotations set by hand to the user even without matching route", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "hand-annotated-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh:               "true",
+						controllers.AnnotationGatewayRoute:              "non-existing-route",
+						controllers.AnnotationPublicGatewayName:         "my-gateways/my-gateway",
+						controllers.AnnotationPublicGatewayExternalHost: "my.gateway.io",
+						controllers.AnnotationPublicGatewayInternalHost: "my-gateway.my-gateways.svc.cluster.local",
+					},
+				},
+			}
+
+			// when
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			// then
+			status := &meshv1alpha1.ProjectMeshStatus{}
+			Eventually(func() []meshv1alpha1.FeatureStatus {
+				_ = cli.Get(context.Background(), types.NamespacedName{Namespace: testNs.Name, Name: "default"}, status)
+
+				return status.Status.Features
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(ContainElement(meshv1alpha1.FeatureStatus{Name: controllers.FeatureGatewayAnnotations, State: meshv1alpha1.FeatureSucceeded}))
+
+			actualTestNs := &corev1.Namespace{}
+			Expect(cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)).To(Succeed())
+			Expect(actualTestNs.Annotations).To(SatisfyAll(
+				HaveKeyWithValue(controllers.AnnotationPublicGatewayExternalHost, "my.gateway.io"),
+				Not(HaveKey(controllers.AnnotationPublicGatewayManaged)),
+			))
+			Expect(eventReasonsFor(testNs)).ToNot(ContainElement(controllers.ReasonGatewayRouteNotFound))
+		})
+
+		It("should add internal gateway host to the namespace", func() {
+			// given
+			testNs = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "plain-meshified-ns",
+					Annotations: map[string]string{
+						controllers.AnnotationServiceMesh: "true",
+					},
+				},
+			}
+
+			// when
+			Expect(cli.Create(context.Background(), testNs)).To(Succeed())
+
+			// then
+			actualTestNs := &corev1.Namespace{}
+			Eventually(func() string {
+				_ = cli.Get(context.Background(), types.NamespacedName{Name: testNs.Name}, actualTestNs)
+
+				return actualTestNs.Annotations[controllers.AnnotationPublicGatewayInternalHost]
+			}).
+				WithTimeout(timeout).
+				WithPolling(interval).
+				Should(Equal("istio-ingressgateway.istio-system.svc.cluster.local"))
+		})
+
+	})
+
+})
+
+func eventReasonsFor(object client.Object) []string {
+	events := &corev1.EventList{}
+	if err := cli.List(context.Background(), events); err != nil {
+		return nil
+	}
+
+	var reasons []string
+
+	for i := range events.Items {
+		if events.Items[i].InvolvedObject.Name == object.GetName() && events.Items[i].InvolvedObject.Kind == "Namespace" {
+			reasons = append(reasons, events.Items[i].Reason)
+		}
+	}
+
+	return reasons
+}
+
+func createReadyControlPlane(namespace, name string) *maistrav1.ServiceMeshControlPlane {
+	smcp := &maistrav1.ServiceMeshControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+	Expect(cli.Create(context.Background(), smcp)).To(Succeed())
+	markControlPlaneReady(smcp)
+
+	return smcp
+}
+
+func markControlPlaneReady(smcp *maistrav1.ServiceMeshControlPlane) {
+	smcp.Status.Conditions = []maistrav1.Condition{
+		{Type: maistrav1.ConditionTypeReady, Status: maistrav1.ConditionStatusTrue},
+	}
+	Expect(cli.Status().Update(context.Background(), smcp)).To(Succeed())
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	maistrav1 "maistra.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const readinessCheckTimeout = 5 * time.Second
+
+// dependencyCheckInterval is how often the mesh dependencies are checked.
+const dependencyCheckInterval = 30 * time.Second
+
+// Names of the readiness checks, used both as readyz endpoints and the check label of readiness metrics.
+const (
+	ReadinessCheckAPIs         = "mesh-apis"
+	ReadinessCheckControlPlane = "control-plane"
+	ReadinessCheckCaches       = "caches"
+)
+
+var (
+	readinessCheckStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "readiness_check_status",
+		Help:      "Outcome of the last readiness check, 1 when passed and 0 when failed.",
+	}, []string{"check"})
+
+	readinessCheckFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "readiness_check_failures_total",
+		Help:      "Number of failed readiness checks.",
+	}, []string{"check"})
+)
+
+func init() { //nolint:gochecknoinits //reason this is how collectors are registered in controller-runtime metrics
+	metrics.Registry.MustRegister(readinessCheckStatus, readinessCheckFailures)
+}
+
+// CacheSyncer waits for informer caches to be synced, as implemented by the manager's cache.
+type CacheSyncer interface {
+	WaitForCacheSync(ctx context.Context) bool
+}
+
+// ReadinessChecks verify that the dependencies of the controller are in place.
+type ReadinessChecks struct {
+	discovery discovery.DiscoveryInterface
+	reader    client.Reader
+	cache     CacheSyncer
+	config    *MeshConfigStore
+}
+
+// NewReadinessChecks creates readiness checks of the controller dependencies.
+func NewReadinessChecks(discovery discovery.DiscoveryInterface, reader client.Reader, cache CacheSyncer, config *MeshConfigStore) *ReadinessChecks {
+	return &ReadinessChecks{
+		discovery: discovery,
+		reader:    reader,
+		cache:     cache,
+		config:    config,
+	}
+}
+
+// Checkers returns all readiness checks by their names, with their outcome exported as metrics.
+func (c *ReadinessChecks) Checkers() map[string]healthz.Checker {
+	checkers := c.DependencyCheckers()
+	checkers[ReadinessCheckCaches] = instrumentedCheck(ReadinessCheckCaches, c.CachesSynced)
+
+	return checkers
+}
+
+// DependencyCheckers returns checks of the mesh dependencies by their names, with their outcome exported as metrics.
+func (c *ReadinessChecks) DependencyCheckers() map[string]healthz.Checker {
+	return map[string]healthz.Checker{
+		ReadinessCheckAPIs:         instrumentedCheck(ReadinessCheckAPIs, c.APIsDiscoverable),
+		ReadinessCheckControlPlane: instrumentedCheck(ReadinessCheckControlPlane, c.ControlPlaneExists),
+	}
+}
+
+// MonitorDependencies periodically runs DependencyCheckers, so that a broken install shows up in the metrics
+// when the mesh dependencies do not gate the readiness.
+func (c *ReadinessChecks) MonitorDependencies(ctx context.Context) error {
+	checks := c.DependencyCheckers()
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/", http.NoBody)
+		if err != nil {
+			return
+		}
+
+		for _, check := range checks {
+			_ = check(req)
+		}
+	}, dependencyCheckInterval)
+
+	return nil
+}
+
+// APIsDiscoverable checks if ServiceMeshMember, ServiceMeshControlPlane and Route APIs are served by the cluster.
+func (c *ReadinessChecks) APIsDiscoverable(_ *http.Request) error {
+	for _, capability := range builtinCapabilities() {
+		served, err := isServed(c.discovery, capability)
+		if err != nil {
+			return err
+		}
+
+		if !served {
+			return errors.Errorf("%s API required by %s capability is not served", capability.GroupVersion, capability.Name)
+		}
+	}
+
+	return nil
+}
+
+// ControlPlaneExists checks if the ServiceMeshControlPlane defined in the controller configuration exists.
+func (c *ReadinessChecks) ControlPlaneExists(req *http.Request) error {
+	ctx, cancel := context.WithTimeout(req.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	config := c.config.Get()
+	key := types.NamespacedName{Name: config.ControlPlaneName, Namespace: config.MeshNamespace}
+
+	return errors.Wrapf(c.reader.Get(ctx, key, &maistrav1.ServiceMeshControlPlane{}), "unable to find ServiceMeshControlPlane %s", key)
+}
+
+// CachesSynced checks if informer caches have been synced.
+func (c *ReadinessChecks) CachesSynced(req *http.Request) error {
+	ctx, cancel := context.WithTimeout(req.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	if !c.cache.WaitForCacheSync(ctx) {
+		return errors.New("informer caches are not synced")
+	}
+
+	return nil
+}
+
+func instrumentedCheck(name string, check healthz.Checker) healthz.Checker {
+	return func(req *http.Request) error {
+		if err := check(req); err != nil {
+			readinessCheckStatus.WithLabelValues(name).Set(0)
+			readinessCheckFailures.WithLabelValues(name).Inc()
+
+			return err
+		}
+
+		readinessCheckStatus.WithLabelValues(name).Set(1)
+
+		return nil
+	}
+}
+
+func containsResource(resources []metav1.APIResource, name string) bool {
+	for i := range resources {
+		if resources[i].Name == name {
+			return true
+		}
+	}
+
+	return false
+}
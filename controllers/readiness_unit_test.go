@@ -0,0 +1,116 @@
+package controllers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/opendatahub-io/odh-project-controller/controllers"
+	"github.com/opendatahub-io/odh-project-controller/test/labels"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	maistrav1 "maistra.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type syncedCache bool
+
+func (s syncedCache) WaitForCacheSync(_ context.Context) bool {
+	return bool(s)
+}
+
+var _ = Describe("Readiness checks", Label(labels.Unit), func() {
+
+	var (
+		request *http.Request
+		config  *controllers.MeshConfigStore
+		scheme  *runtime.Scheme
+	)
+
+	servedAPIs := func(resources ...*metav1.APIResourceList) *fakediscovery.FakeDiscovery {
+		return &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: resources}}
+	}
+
+	maistraAPI := &metav1.APIResourceList{
+		GroupVersion: "maistra.io/v1",
+		APIResources: []metav1.APIResource{{Name: "servicemeshmembers"}, {Name: "servicemeshcontrolplanes"}},
+	}
+
+	routeAPI := &metav1.APIResourceList{
+		GroupVersion: "route.openshift.io/v1",
+		APIResources: []metav1.APIResource{{Name: "routes"}},
+	}
+
+	readinessChecks := func(discovery *fakediscovery.FakeDiscovery, objects ...client.Object) *controllers.ReadinessChecks {
+		reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+
+		return controllers.NewReadinessChecks(discovery, reader, syncedCache(true), config)
+	}
+
+	BeforeEach(func() {
+		request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+		config = controllers.NewMeshConfigStore(controllers.NewMeshConfigFromEnv(), types.NamespacedName{})
+		scheme = runtime.NewScheme()
+		Expect(maistrav1.AddToScheme(scheme)).To(Succeed())
+	})
+
+	It("should pass when mesh and route APIs are served", func() {
+		Expect(readinessChecks(servedAPIs(maistraAPI, routeAPI)).APIsDiscoverable(request)).To(Succeed())
+	})
+
+	It("should fail when route API is not served", func() {
+		Expect(readinessChecks(servedAPIs(maistraAPI)).APIsDiscoverable(request)).
+			To(MatchError(ContainSubstring("route.openshift.io/v1")))
+	})
+
+	It("should pass when configured control plane exists", func() {
+		// given
+		smcp := &maistrav1.ServiceMeshControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: "basic", Namespace: "istio-system"},
+		}
+
+		// when
+		checks := readinessChecks(servedAPIs(), smcp)
+
+		// then
+		Expect(checks.ControlPlaneExists(request)).To(Succeed())
+	})
+
+	It("should fail when configured control plane does not exist", func() {
+		Expect(readinessChecks(servedAPIs()).ControlPlaneExists(request)).
+			To(MatchError(ContainSubstring("istio-system/basic")))
+	})
+
+	It("should not be ready when mesh APIs and control plane are missing", func() {
+		// given
+		checks := readinessChecks(servedAPIs())
+
+		// when
+		checkers := checks.Checkers()
+
+		// then
+		Expect(checkers).To(HaveLen(3))
+		Expect(checkers[controllers.ReadinessCheckCaches](request)).To(Succeed())
+		Expect(checkers[controllers.ReadinessCheckAPIs](request)).To(HaveOccurred())
+		Expect(checkers[controllers.ReadinessCheckControlPlane](request)).To(HaveOccurred())
+	})
+
+	It("should fail when caches are not synced", func() {
+		// given
+		reader := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		// when
+		checks := controllers.NewReadinessChecks(servedAPIs(), reader, syncedCache(false), config)
+
+		// then
+		Expect(checks.CachesSynced(request)).To(HaveOccurred())
+	})
+
+})
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"fmt"
+	"io"
+
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	maistrav1 "maistra.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// RenderedNamespace holds changes the controller would make for the namespace.
+type RenderedNamespace struct {
+	Namespace string
+	// MeshMember is the ServiceMeshMember created for the namespace, nil when the namespace is not enrolled in the mesh.
+	MeshMember *maistrav1.ServiceMeshMember
+	// AnnotationChanges lists gateway annotations which would be set on the namespace.
+	AnnotationChanges []Change
+}
+
+// Render works out, without reaching the cluster, the ServiceMeshMember and gateway annotations the controller would
+// reconcile for the namespace. Gateway annotations are rendered only when gateway routes are provided.
+// ProjectMeshPolicies and readiness of the control plane are not taken into account, hence control plane
+// selected through the namespace annotation is rendered only when it is the configured one.
+func Render(namespace *v1.Namespace, config MeshConfig, reserved *ReservedNamespaces, routes []routev1.Route) (RenderedNamespace, error) {
+	rendered := RenderedNamespace{Namespace: namespace.Name}
+
+	if serviceMeshIsNotEnabled(namespace.ObjectMeta) || reserved.IsReserved(namespace) {
+		return rendered, nil
+	}
+
+	controlPlane, err := controlPlaneRef(namespace, config, nil)
+	if err != nil && !isControlPlaneNotAllowed(err) {
+		return rendered, err
+	}
+
+	rendered.MeshMember = newServiceMeshMember(namespace, controlPlane)
+	rendered.MeshMember.TypeMeta.APIVersion = maistrav1.SchemeGroupVersion.String()
+	rendered.MeshMember.TypeMeta.Kind = "ServiceMeshMember"
+	// Namespace read from the file has no UID, which the owner reference needs to be valid
+	rendered.MeshMember.OwnerReferences = nil
+
+	if len(routes) == 0 {
+		return rendered, nil
+	}
+
+	managed, err := managedGatewayAnnotations(namespace)
+	if err != nil {
+		return rendered, err
+	}
+
+	matching, err := matchingGatewayRoutes(routes, config)
+	if err != nil {
+		return rendered, err
+	}
+
+	if len(matching) == 0 {
+		if gatewayAnnotationsSetByUser(namespace, managed) {
+			return rendered, nil
+		}
+
+		return rendered, errors.Errorf("no route matching %q found in namespace %s", config.GatewayRouteSelector, config.gatewayRouteNamespace())
+	}
+
+	route, err := SelectGatewayRoute(matching, config.GatewayRouteStrategy, namespace.Annotations[AnnotationGatewayRoute])
+	if err != nil {
+		if gatewayAnnotationsSetByUser(namespace, managed) {
+			return rendered, nil
+		}
+
+		return rendered, err
+	}
+
+	updated := namespace.DeepCopy()
+	if syncGatewayAnnotations(updated, gatewayAnnotationsFor(route), managed) {
+		rendered.AnnotationChanges = mapChanges("metadata.annotations", namespace.Annotations, updated.Annotations)
+	}
+
+	return rendered, nil
+}
+
+func matchingGatewayRoutes(routes []routev1.Route, config MeshConfig) ([]routev1.Route, error) {
+	selector, err := labels.Parse(config.GatewayRouteSelector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid gateway route selector %q", config.GatewayRouteSelector)
+	}
+
+	var matching []routev1.Route
+
+	for _, route := range routes {
+		if route.Namespace == config.gatewayRouteNamespace() && selector.Matches(labels.Set(route.Labels)) {
+			matching = append(matching, route)
+		}
+	}
+
+	return matching, nil
+}
+
+// WriteRendered prints rendered namespaces as a YAML stream of ServiceMeshMembers, with annotation changes in comments.
+func WriteRendered(w io.Writer, rendered []RenderedNamespace) error {
+	for _, namespace := range rendered {
+		if _, err := fmt.Fprintf(w, "# Namespace: %s\n", namespace.Namespace); err != nil {
+			return errors.Wrap(err, "failed writing rendered namespace")
+		}
+
+		if namespace.MeshMember == nil {
+			if _, err := fmt.Fprintln(w, "# Not enrolled in the mesh"); err != nil {
+				return errors.Wrap(err, "failed writing rendered namespace")
+			}
+
+			continue
+		}
+
+		for _, change := range namespace.AnnotationChanges {
+			if _, err := fmt.Fprintf(w, "# %s\n", change); err != nil {
+				return errors.Wrap(err, "failed writing rendered namespace")
+			}
+		}
+
+		member, err := yaml.Marshal(namespace.MeshMember)
+		if err != nil {
+			return errors.Wrap(err, "failed converting ServiceMeshMember to YAML")
+		}
+
+		if _, err := fmt.Fprintf(w, "---\n%s", member); err != nil {
+			return errors.Wrap(err, "failed writing rendered namespace")
+		}
+	}
+
+	return nil
+}
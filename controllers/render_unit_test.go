@@ -0,0 +1,146 @@
+package controllers_test
+
+import (
+	"github.com/opendatahub-io/odh-project-controller/controllers"
+	"github.com/opendatahub-io/odh-project-controller/test/labels"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rendering namespace changes", Label(labels.Unit), func() {
+
+	config := controllers.MeshConfig{
+		ControlPlaneName:     "basic",
+		MeshNamespace:        "istio-system",
+		GatewayRouteSelector: "app=odh-dashboard",
+		GatewayRouteStrategy: controllers.RouteSelectionOldest,
+	}
+
+	meshAwareNamespace := func(name string, annotations map[string]string) *corev1.Namespace {
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{controllers.AnnotationServiceMesh: "true"},
+			},
+		}
+		for key, value := range annotations {
+			namespace.Annotations[key] = value
+		}
+
+		return namespace
+	}
+
+	routes := []routev1.Route{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "odh-dashboard",
+				Namespace: "istio-system",
+				Labels:    map[string]string{"app": "odh-dashboard"},
+			},
+			Spec: routev1.RouteSpec{
+				Host: "dashboard.apps.example.com",
+				To:   routev1.RouteTargetReference{Name: "istio-ingressgateway"},
+			},
+		},
+	}
+
+	It("should render mesh member and gateway annotations for mesh-aware namespace", func() {
+		// when
+		rendered, err := controllers.Render(meshAwareNamespace("render-ns", nil), config, controllers.DefaultReservedNamespaces(), routes)
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rendered.MeshMember).ToNot(BeNil())
+		Expect(rendered.MeshMember.Namespace).To(Equal("render-ns"))
+		Expect(rendered.MeshMember.Spec.ControlPlaneRef.Name).To(Equal("basic"))
+		Expect(rendered.MeshMember.OwnerReferences).To(BeEmpty())
+		Expect(rendered.AnnotationChanges).To(ContainElement(controllers.Change{
+			Field: "metadata.annotations[" + controllers.AnnotationPublicGatewayExternalHost + "]",
+			To:    "dashboard.apps.example.com",
+		}))
+	})
+
+	It("should render removal of gateway annotation no longer derived from the route", func() {
+		// given
+		namespace := meshAwareNamespace("render-ns", map[string]string{
+			controllers.AnnotationPublicGatewayName:         "opendatahub/odh-gateway",
+			controllers.AnnotationPublicGatewayExternalHost: "dashboard.apps.example.com",
+			controllers.AnnotationPublicGatewayInternalHost: "istio-ingressgateway.istio-system.svc.cluster.local",
+			controllers.AnnotationPublicGatewayManaged: `{"` + controllers.AnnotationPublicGatewayName + `":"opendatahub/odh-gateway",` +
+				`"` + controllers.AnnotationPublicGatewayExternalHost + `":"dashboard.apps.example.com",` +
+				`"` + controllers.AnnotationPublicGatewayInternalHost + `":"istio-ingressgateway.istio-system.svc.cluster.local"}`,
+		})
+
+		// when
+		rendered, err := controllers.Render(namespace, config, controllers.DefaultReservedNamespaces(), routes)
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rendered.AnnotationChanges).To(ContainElement(controllers.Change{
+			Field: "metadata.annotations[" + controllers.AnnotationPublicGatewayName + "]",
+			From:  "opendatahub/odh-gateway",
+		}))
+	})
+
+	It("should render mesh member of the configured control plane when namespace selects another one", func() {
+		// given
+		namespace := meshAwareNamespace("render-ns", map[string]string{controllers.AnnotationControlPlane: "regulated-mesh/restricted"})
+
+		// when
+		rendered, err := controllers.Render(namespace, config, controllers.DefaultReservedNamespaces(), nil)
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rendered.MeshMember.Spec.ControlPlaneRef.Namespace).To(Equal("istio-system"))
+		Expect(rendered.MeshMember.Spec.ControlPlaneRef.Name).To(Equal("basic"))
+		Expect(rendered.AnnotationChanges).To(BeEmpty())
+	})
+
+	It("should not render anything for reserved namespace", func() {
+		// when
+		rendered, err := controllers.Render(meshAwareNamespace("openshift-monitoring", nil), config, controllers.DefaultReservedNamespaces(), routes)
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rendered.MeshMember).To(BeNil())
+		Expect(rendered.AnnotationChanges).To(BeEmpty())
+	})
+
+	It("should not render anything for namespace of the mesh", func() {
+		// given
+		reserved, err := controllers.NewReservedNamespaces(nil, nil, "")
+		Expect(err).ToNot(HaveOccurred())
+		reserved.MeshNamespace = func() string {
+			return "my-mesh-system"
+		}
+
+		// when
+		rendered, err := controllers.Render(meshAwareNamespace("my-mesh-system", nil), config, reserved, routes)
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(rendered.MeshMember).To(BeNil())
+	})
+
+	It("should fail when none of the routes matches the selector", func() {
+		// given
+		namespace := meshAwareNamespace("render-ns", nil)
+		unmatched := controllers.MeshConfig{
+			ControlPlaneName:     "basic",
+			MeshNamespace:        "istio-system",
+			GatewayRouteSelector: "app=non-existing",
+			GatewayRouteStrategy: controllers.RouteSelectionOldest,
+		}
+
+		// when
+		_, err := controllers.Render(namespace, unmatched, controllers.DefaultReservedNamespaces(), routes)
+
+		// then
+		Expect(err).To(MatchError(ContainSubstring("no route matching")))
+	})
+
+})
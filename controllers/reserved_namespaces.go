@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultReservedNamespacePatterns returns patterns of namespace names which are never enrolled in the mesh,
+// unless configured otherwise.
+func DefaultReservedNamespacePatterns() []string {
+	return []string{"openshift", "istio-system", "kube-.*", "openshift-.*"}
+}
+
+// ReservedNamespaces holds the rules deciding which namespaces are never enrolled in the mesh.
+type ReservedNamespaces struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+	label   string
+	// MeshNamespace returns the namespace of the mesh currently in use, which is always reserved.
+	MeshNamespace func() string
+}
+
+// NewReservedNamespaces compiles the rules for reserved namespaces. Namespace is reserved when its name matches
+// any of include patterns but none of exclude patterns, or when it is marked with the label set to "true".
+// Patterns are matched against the whole namespace name.
+func NewReservedNamespaces(include, exclude []string, label string) (*ReservedNamespaces, error) {
+	includeRegexps, err := compilePatterns(include)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid reserved namespace pattern")
+	}
+
+	excludeRegexps, err := compilePatterns(exclude)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid excluded namespace pattern")
+	}
+
+	return &ReservedNamespaces{
+		include: includeRegexps,
+		exclude: excludeRegexps,
+		label:   label,
+	}, nil
+}
+
+// DefaultReservedNamespaces creates rules reserving namespaces matching DefaultReservedNamespacePatterns
+// or labeled with LabelReservedNamespace.
+func DefaultReservedNamespaces() *ReservedNamespaces {
+	reserved, err := NewReservedNamespaces(DefaultReservedNamespacePatterns(), nil, LabelReservedNamespace)
+	if err != nil {
+		panic(err)
+	}
+
+	return reserved
+}
+
+// IsReserved checks if the namespace must not be enrolled in the mesh.
+func (r *ReservedNamespaces) IsReserved(namespace client.Object) bool {
+	if r.MeshNamespace != nil && namespace.GetName() == r.MeshNamespace() {
+		return true
+	}
+
+	if r.label != "" {
+		if reserved, _ := strconv.ParseBool(namespace.GetLabels()[r.label]); reserved {
+			return true
+		}
+	}
+
+	return r.isReservedName(namespace.GetName())
+}
+
+func (r *ReservedNamespaces) isReservedName(name string) bool {
+	return matchesAny(r.include, name) && !matchesAny(r.exclude, name)
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	regexps := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		regex, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed compiling %q", pattern)
+		}
+
+		regexps = append(regexps, regex)
+	}
+
+	return regexps, nil
+}
+
+func matchesAny(regexps []*regexp.Regexp, value string) bool {
+	for _, regex := range regexps {
+		if regex.MatchString(value) {
+			return true
+		}
+	}
+
+	return false
+}
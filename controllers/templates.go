@@ -0,0 +1,476 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"text/template"
+
+	"github.com/manifestival/manifestival"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	k8serrs "k8s.io/apimachinery/pkg/util/errors"
+	maistrav1 "maistra.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// MeshTemplatesConfigMapName is the name of the ConfigMap holding templates of resources created in every enrolled namespace.
+// It is looked up in the namespace of the mesh configuration ConfigMap. Role of the controller has to allow managing
+// and watching kinds the templates produce, Istio Sidecars, PeerAuthentications and AuthorizationPolicies are allowed by default.
+const MeshTemplatesConfigMapName = "service-mesh-templates"
+
+// TemplateData is passed to the templates of per-namespace resources.
+type TemplateData struct {
+	Namespace    TemplateNamespace
+	Mesh         MeshConfig
+	ControlPlane maistrav1.ServiceMeshControlPlaneRef
+}
+
+// TemplateNamespace describes the namespace resources are rendered for.
+type TemplateNamespace struct {
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// templateResource identifies the resource created from a template, as recorded in AnnotationTemplateResources.
+type templateResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+func templateResourceOf(object *unstructured.Unstructured) templateResource {
+	return templateResource{APIVersion: object.GetAPIVersion(), Kind: object.GetKind(), Name: object.GetName()}
+}
+
+// RenderTemplates executes templates keyed by their names and reads resources they produce. Resources are placed
+// in the namespace and labeled with the name of the template they come from.
+func RenderTemplates(templates map[string]string, data TemplateData) ([]unstructured.Unstructured, error) {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var resources []unstructured.Unstructured
+
+	rendered := map[templateResource]string{}
+
+	for _, name := range names {
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(templates[name])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid template %s", name)
+		}
+
+		var content bytes.Buffer
+		if err := tmpl.Execute(&content, data); err != nil {
+			return nil, errors.Wrapf(err, "failed rendering template %s", name)
+		}
+
+		manifest, err := manifestival.ManifestFrom(manifestival.Reader(&content))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed reading resources rendered from template %s", name)
+		}
+
+		for _, resource := range manifest.Resources() {
+			resource.SetNamespace(data.Namespace.Name)
+
+			labels := resource.GetLabels()
+			if labels == nil {
+				labels = map[string]string{}
+			}
+
+			labels[LabelManagedBy] = ManagedByValue
+			labels[LabelTemplate] = name
+			resource.SetLabels(labels)
+
+			id := templateResourceOf(&resource)
+			if other, duplicated := rendered[id]; duplicated {
+				return nil, errors.Errorf("%s %s rendered from both %s and %s templates", id.Kind, id.Name, other, name)
+			}
+
+			rendered[id] = name
+			resources = append(resources, resource)
+		}
+	}
+
+	return resources, nil
+}
+
+// applyTemplates creates resources rendered from the templates in the namespace, restores them when modified
+// out of band and removes the ones which are no longer rendered.
+func (r *OpenshiftServiceMeshReconciler) applyTemplates(ctx context.Context, namespace *v1.Namespace) error {
+	log := r.Log.WithValues("feature", FeatureTemplates, "namespace", namespace.Name)
+
+	templates, err := r.meshTemplates(ctx)
+	if err != nil {
+		return err
+	}
+
+	config, err := r.meshConfigFor(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	controlPlane, err := r.controlPlaneFor(ctx, namespace, config)
+	if err != nil && !isControlPlaneNotAllowed(err) {
+		return err
+	}
+
+	desired, err := RenderTemplates(templates, TemplateData{
+		Namespace: TemplateNamespace{
+			Name:        namespace.Name,
+			Labels:      namespace.Labels,
+			Annotations: namespace.Annotations,
+		},
+		Mesh:         config,
+		ControlPlane: controlPlane,
+	})
+	if err != nil {
+		log.Error(err, "Unable to render templates")
+		r.Recorder.Event(namespace, v1.EventTypeWarning, ReasonTemplateFailed, err.Error())
+
+		return err
+	}
+
+	var errs []error
+
+	applied := map[templateResource]bool{}
+
+	for i := range desired {
+		desired[i].SetOwnerReferences([]metav1.OwnerReference{
+			*metav1.NewControllerRef(namespace, v1.SchemeGroupVersion.WithKind("Namespace")),
+		})
+
+		if err := r.watchTemplateKind(desired[i].GroupVersionKind()); err != nil {
+			log.Error(err, "Unable to watch templated resources, their drift is repaired on the next reconciliation", "kind", desired[i].GetKind())
+		}
+
+		if err := r.applyTemplateResource(ctx, namespace, &desired[i]); err != nil {
+			log.Error(err, "Unable to apply templated resource", "kind", desired[i].GetKind(), "name", desired[i].GetName())
+			errs = append(errs, err)
+		}
+
+		applied[templateResourceOf(&desired[i])] = true
+	}
+
+	recorded, err := templateResourcesOf(namespace)
+	if err != nil {
+		log.Error(err, "Ignoring malformed record of templated resources")
+	}
+
+	for _, resource := range recorded {
+		if applied[resource] {
+			continue
+		}
+
+		if err := r.deleteTemplateResource(ctx, namespace, resource); err != nil {
+			// Keep it recorded, so that removal is retried
+			applied[resource] = true
+			errs = append(errs, err)
+		}
+	}
+
+	if err := r.recordTemplateResources(ctx, namespace, applied); err != nil {
+		errs = append(errs, err)
+	}
+
+	return k8serrs.NewAggregate(errs)
+}
+
+func (r *OpenshiftServiceMeshReconciler) applyTemplateResource(ctx context.Context, namespace *v1.Namespace, desired *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(desired.GroupVersionKind())
+
+	if err := r.templateResourceReader().Get(ctx, client.ObjectKeyFromObject(desired), existing); err != nil {
+		if !apierrs.IsNotFound(err) {
+			return errors.Wrapf(err, "failed getting %s %s", desired.GetKind(), desired.GetName())
+		}
+
+		if r.DryRun {
+			r.reportDryRun(namespace, FeatureTemplates, OperationCreate, fmt.Sprintf("create %s %s", desired.GetKind(), desired.GetName()), nil)
+
+			return nil
+		}
+
+		// Resource created in the previous reconciliation might not have reached the cache yet,
+		// its watch event requeues the namespace anyway
+		if err := r.Create(ctx, desired); err != nil {
+			if apierrs.IsAlreadyExists(err) {
+				return nil
+			}
+
+			return errors.Wrapf(err, "failed creating %s %s", desired.GetKind(), desired.GetName())
+		}
+
+		r.Recorder.Eventf(namespace, v1.EventTypeNormal, ReasonTemplateResourceApplied, "Created %s %s from template %s",
+			desired.GetKind(), desired.GetName(), desired.GetLabels()[LabelTemplate])
+
+		return nil
+	}
+
+	if !isManagedByController(existing) {
+		return errors.Errorf("%s %s already exists and is not managed by the controller", desired.GetKind(), desired.GetName())
+	}
+
+	if !templateResourceDrifted(desired, existing) {
+		return nil
+	}
+
+	if r.DryRun {
+		r.reportDryRun(namespace, FeatureTemplates, OperationDriftRepair, fmt.Sprintf("restore %s %s", desired.GetKind(), desired.GetName()), nil)
+
+		return nil
+	}
+
+	for field, value := range desired.Object {
+		if !isMetadataField(field) {
+			existing.Object[field] = value
+		}
+	}
+
+	existing.SetLabels(mergeStrings(existing.GetLabels(), desired.GetLabels()))
+	existing.SetAnnotations(mergeStrings(existing.GetAnnotations(), desired.GetAnnotations()))
+	existing.SetOwnerReferences(desired.GetOwnerReferences())
+
+	if err := r.Update(ctx, existing); err != nil {
+		return errors.Wrapf(err, "failed updating %s %s", desired.GetKind(), desired.GetName())
+	}
+
+	r.Recorder.Eventf(namespace, v1.EventTypeNormal, ReasonTemplateResourceApplied, "Restored %s %s from template %s",
+		desired.GetKind(), desired.GetName(), desired.GetLabels()[LabelTemplate])
+
+	return nil
+}
+
+// templateResourceDrifted checks if fields set by the template have been changed. Fields not set by the template,
+// e.g. defaulted by the API server, are ignored.
+func templateResourceDrifted(desired, existing *unstructured.Unstructured) bool {
+	for field, value := range desired.Object {
+		if !isMetadataField(field) && !equality.Semantic.DeepDerivative(value, existing.Object[field]) {
+			return true
+		}
+	}
+
+	return !equality.Semantic.DeepDerivative(desired.GetLabels(), existing.GetLabels()) ||
+		!equality.Semantic.DeepDerivative(desired.GetAnnotations(), existing.GetAnnotations()) ||
+		!equality.Semantic.DeepEqual(desired.GetOwnerReferences(), existing.GetOwnerReferences())
+}
+
+func isMetadataField(field string) bool {
+	return field == "apiVersion" || field == "kind" || field == "metadata" || field == "status"
+}
+
+func mergeStrings(current, desired map[string]string) map[string]string {
+	if len(desired) == 0 {
+		return current
+	}
+
+	merged := map[string]string{}
+	for key, value := range current {
+		merged[key] = value
+	}
+
+	for key, value := range desired {
+		merged[key] = value
+	}
+
+	return merged
+}
+
+// removeTemplateResources deletes all resources created from the templates in the namespace.
+func (r *OpenshiftServiceMeshReconciler) removeTemplateResources(ctx context.Context, namespace *v1.Namespace) error {
+	recorded, err := templateResourcesOf(namespace)
+	if err != nil {
+		r.Log.Error(err, "Ignoring malformed record of templated resources", "namespace", namespace.Name)
+	}
+
+	var errs []error
+
+	for _, resource := range recorded {
+		errs = append(errs, r.deleteTemplateResource(ctx, namespace, resource))
+	}
+
+	if err := k8serrs.NewAggregate(errs); err != nil {
+		return err
+	}
+
+	if _, exists := namespace.Annotations[AnnotationTemplateResources]; !exists || r.DryRun {
+		return nil
+	}
+
+	delete(namespace.Annotations, AnnotationTemplateResources)
+
+	return errors.Wrap(r.Update(ctx, namespace), "failed removing record of templated resources from namespace")
+}
+
+func (r *OpenshiftServiceMeshReconciler) deleteTemplateResource(ctx context.Context, namespace *v1.Namespace, resource templateResource) error {
+	if r.DryRun {
+		r.reportDryRun(namespace, FeatureTemplates, OperationDelete, fmt.Sprintf("delete %s %s", resource.Kind, resource.Name), nil)
+
+		return nil
+	}
+
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion(resource.APIVersion)
+	object.SetKind(resource.Kind)
+	object.SetNamespace(namespace.Name)
+	object.SetName(resource.Name)
+
+	if err := r.Delete(ctx, object); err != nil && !apierrs.IsNotFound(err) {
+		return errors.Wrapf(err, "failed deleting %s %s", resource.Kind, resource.Name)
+	}
+
+	r.Recorder.Eventf(namespace, v1.EventTypeNormal, ReasonTemplateResourceRemoved, "Deleted %s %s no longer rendered from templates",
+		resource.Kind, resource.Name)
+
+	return nil
+}
+
+// recordTemplateResources keeps track of the resources created from the templates, so that they can be removed
+// when the template producing them is gone.
+func (r *OpenshiftServiceMeshReconciler) recordTemplateResources(ctx context.Context, namespace *v1.Namespace, applied map[templateResource]bool) error {
+	resources := make([]templateResource, 0, len(applied))
+	for resource := range applied {
+		resources = append(resources, resource)
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		return fmt.Sprint(resources[i]) < fmt.Sprint(resources[j])
+	})
+
+	var record string
+
+	if len(resources) > 0 {
+		content, _ := json.Marshal(resources) //nolint:errchkjson //reason struct of strings is always serializable
+		record = string(content)
+	}
+
+	if r.DryRun || namespace.Annotations[AnnotationTemplateResources] == record {
+		return nil
+	}
+
+	if record == "" {
+		delete(namespace.Annotations, AnnotationTemplateResources)
+	} else {
+		if namespace.Annotations == nil {
+			namespace.Annotations = map[string]string{}
+		}
+
+		namespace.Annotations[AnnotationTemplateResources] = record
+	}
+
+	return errors.Wrap(r.Update(ctx, namespace), "failed recording templated resources on namespace")
+}
+
+// templateResourcesOf reads resources previously created from the templates in the namespace.
+func templateResourcesOf(namespace *v1.Namespace) ([]templateResource, error) {
+	record, exists := namespace.Annotations[AnnotationTemplateResources]
+	if !exists {
+		return nil, nil
+	}
+
+	var resources []templateResource
+	if err := json.Unmarshal([]byte(record), &resources); err != nil {
+		return nil, errors.Wrapf(err, "invalid %s annotation", AnnotationTemplateResources)
+	}
+
+	return resources, nil
+}
+
+// meshTemplates reads templates of per-namespace resources. No templates are defined when their ConfigMap does not exist.
+func (r *OpenshiftServiceMeshReconciler) meshTemplates(ctx context.Context) (map[string]string, error) {
+	source := r.templatesSource()
+	if source.Namespace == "" {
+		return nil, nil
+	}
+
+	configMap := &v1.ConfigMap{}
+	if err := r.Get(ctx, source, configMap); err != nil {
+		if apierrs.IsNotFound(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrap(err, "unable to fetch mesh templates")
+	}
+
+	return configMap.Data, nil
+}
+
+func (r *OpenshiftServiceMeshReconciler) templatesSource() types.NamespacedName {
+	return types.NamespacedName{Namespace: r.Config.Source().Namespace, Name: MeshTemplatesConfigMapName}
+}
+
+// templateResourceReader reads templated resources through the informers started by watchTemplateKind. The client
+// of the manager reads unstructured objects straight from the API server.
+func (r *OpenshiftServiceMeshReconciler) templateResourceReader() client.Reader {
+	if r.cache == nil {
+		return r.Client
+	}
+
+	return r.cache
+}
+
+// watchTemplateKind starts watching resources of the kind produced by the templates, so that they are restored
+// straight away when modified or deleted out of band. Watched resources are cached as a whole, as they are compared
+// with the templates on every reconciliation.
+func (r *OpenshiftServiceMeshReconciler) watchTemplateKind(gvk schema.GroupVersionKind) error {
+	if r.controller == nil {
+		return nil
+	}
+
+	r.templateKindsMu.Lock()
+	defer r.templateKindsMu.Unlock()
+
+	if r.templateKinds[gvk] {
+		return nil
+	}
+
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(gvk)
+
+	if err := r.controller.Watch(source.Kind(r.cache, object), handler.EnqueueRequestsFromMapFunc(TemplateResourceToNamespace)); err != nil {
+		return errors.Wrapf(err, "failed watching %s", gvk)
+	}
+
+	if r.templateKinds == nil {
+		r.templateKinds = map[schema.GroupVersionKind]bool{}
+	}
+
+	r.templateKinds[gvk] = true
+
+	return nil
+}
+
+// TemplateResourceToNamespace maps events of resources created from the templates back to the namespace owning them.
+func TemplateResourceToNamespace(_ context.Context, object client.Object) []reconcile.Request {
+	if _, templated := object.GetLabels()[LabelTemplate]; !templated || !isOwnedByNamespace(object) {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: object.GetNamespace()}},
+	}
+}
+
+// templatesChanged requeues all mesh-aware namespaces when the templates of per-namespace resources change.
+func (r *OpenshiftServiceMeshReconciler) templatesChanged(ctx context.Context, object client.Object) []reconcile.Request {
+	if client.ObjectKeyFromObject(object) != r.templatesSource() {
+		return nil
+	}
+
+	return r.meshAwareNamespaceRequests(ctx)
+}
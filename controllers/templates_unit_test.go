@@ -0,0 +1,118 @@
+package controllers_test
+
+import (
+	"context"
+
+	"github.com/opendatahub-io/odh-project-controller/controllers"
+	"github.com/opendatahub-io/odh-project-controller/test/labels"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	maistrav1 "maistra.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rendering templates of namespace resources", Label(labels.Unit), func() {
+
+	data := controllers.TemplateData{
+		Namespace: controllers.TemplateNamespace{
+			Name:   "templated-ns",
+			Labels: map[string]string{"team": "data-science"},
+		},
+		Mesh:         controllers.NewMeshConfigFromEnv(),
+		ControlPlane: maistrav1.ServiceMeshControlPlaneRef{Namespace: "istio-system", Name: "basic"},
+	}
+
+	It("should render every resource of every template into the namespace", func() {
+		// given
+		templates := map[string]string{
+			"peer-authentication": `
+apiVersion: security.istio.io/v1beta1
+kind: PeerAuthentication
+metadata:
+  name: default
+spec:
+  mtls:
+    mode: STRICT
+`,
+			"sidecar": `
+apiVersion: networking.istio.io/v1beta1
+kind: Sidecar
+metadata:
+  name: default
+  labels:
+    team: {{ index .Namespace.Labels "team" }}
+spec:
+  egress:
+  - hosts:
+    - "./*"
+    - "{{ .ControlPlane.Namespace }}/*"
+---
+apiVersion: networking.istio.io/v1beta1
+kind: Sidecar
+metadata:
+  name: restricted
+`,
+		}
+
+		// when
+		resources, err := controllers.RenderTemplates(templates, data)
+
+		// then
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resources).To(HaveLen(3))
+
+		peerAuthentication, sidecar := resources[0], resources[1]
+		Expect(peerAuthentication.GetKind()).To(Equal("PeerAuthentication"))
+		Expect(sidecar.GetNamespace()).To(Equal("templated-ns"))
+		Expect(sidecar.GetLabels()).To(Equal(map[string]string{
+			"team":                     "data-science",
+			controllers.LabelManagedBy: controllers.ManagedByValue,
+			controllers.LabelTemplate:  "sidecar",
+		}))
+		Expect(sidecar.Object["spec"]).To(HaveKeyWithValue("egress", ContainElement(
+			HaveKeyWithValue("hosts", ConsistOf("./*", "istio-system/*")),
+		)))
+	})
+
+	DescribeTable("it should reject",
+		func(templates map[string]string, expectedErr string) {
+			_, err := controllers.RenderTemplates(templates, data)
+			Expect(err).To(MatchError(ContainSubstring(expectedErr)))
+		},
+		Entry("invalid template", map[string]string{"sidecar": "{{ .Namespace.Name"}, "invalid template sidecar"),
+		Entry("template referring to unknown data", map[string]string{"sidecar": "{{ .Unknown }}"}, "failed rendering template sidecar"),
+		Entry("resource rendered from more than one template", map[string]string{
+			"first":  "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n",
+			"second": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n",
+		}, "ConfigMap settings rendered from both first and second templates"),
+	)
+
+	It("should map events of templated resource to the namespace owning it", func() {
+		// given
+		owner := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "templated-ns"}}
+		templated := &metav1.PartialObjectMetadata{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "default",
+				Namespace: "templated-ns",
+				Labels:    map[string]string{controllers.LabelTemplate: "sidecar"},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(owner, corev1.SchemeGroupVersion.WithKind("Namespace")),
+				},
+			},
+		}
+		untemplated := templated.DeepCopy()
+		untemplated.Labels = nil
+
+		// when
+		requests := controllers.TemplateResourceToNamespace(context.Background(), templated)
+
+		// then
+		Expect(requests).To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "templated-ns"}}))
+		Expect(controllers.TemplateResourceToNamespace(context.Background(), untemplated)).To(BeEmpty())
+	})
+
+})
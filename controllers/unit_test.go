@@ -1,8 +1,16 @@
 package controllers_test
 
 import (
+	"context"
+	"time"
+
 	"github.com/opendatahub-io/odh-project-controller/controllers"
 	"github.com/opendatahub-io/odh-project-controller/test/labels"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	maistrav1 "maistra.io/api/core/v1"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -26,4 +34,193 @@ var _ = Describe("Controller helper functions", Label(labels.Unit), func() {
 
 	})
 
+	When("Parsing control plane reference", func() {
+
+		It("should read namespace and name of the control plane", func() {
+			// when
+			ref, err := controllers.ParseControlPlaneRef("regulated-mesh/restricted")
+
+			// then
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ref.Namespace).To(Equal("regulated-mesh"))
+			Expect(ref.Name).To(Equal("restricted"))
+		})
+
+		DescribeTable("it should reject malformed references",
+			func(value string) {
+				_, err := controllers.ParseControlPlaneRef(value)
+				Expect(err).To(HaveOccurred())
+			},
+			Entry("for name only", "restricted"),
+			Entry("for missing name", "regulated-mesh/"),
+			Entry("for missing namespace", "/restricted"),
+			Entry("for too many segments", "regulated-mesh/restricted/v2"),
+			Entry("for invalid namespace", "Regulated_Mesh/restricted"),
+		)
+
+	})
+
+	When("Selecting gateway route", func() {
+
+		routeCreatedAt := func(name string, created time.Time) routev1.Route {
+			return routev1.Route{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              name,
+					CreationTimestamp: metav1.NewTime(created),
+				},
+			}
+		}
+
+		now := time.Now()
+		routes := []routev1.Route{
+			routeCreatedAt("recent", now),
+			routeCreatedAt("oldest", now.Add(-2*time.Hour)),
+			routeCreatedAt("older", now.Add(-1*time.Hour)),
+		}
+
+		withPriority := func(route routev1.Route, priority string) routev1.Route {
+			route.Labels = map[string]string{controllers.LabelGatewayRoutePriority: priority}
+
+			return route
+		}
+
+		DescribeTable("it should pick route according to strategy",
+			func(strategy controllers.RouteSelectionStrategy, expected string) {
+				route, err := controllers.SelectGatewayRoute(routes, strategy, "")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(route.Name).To(Equal(expected))
+			},
+			Entry("for first strategy", controllers.RouteSelectionFirst, "recent"),
+			Entry("for oldest strategy", controllers.RouteSelectionOldest, "oldest"),
+		)
+
+		It("should pick route named by the namespace regardless of strategy", func() {
+			route, err := controllers.SelectGatewayRoute(routes, controllers.RouteSelectionUnique, "older")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(route.Name).To(Equal("older"))
+		})
+
+		It("should fail when route named by the namespace does not match", func() {
+			_, err := controllers.SelectGatewayRoute(routes, controllers.RouteSelectionOldest, "non-existing")
+			Expect(err).To(BeAssignableToTypeOf(&controllers.RouteSelectionError{}))
+		})
+
+		It("should pick route with the highest priority", func() {
+			prioritized := []routev1.Route{
+				withPriority(routes[0], "10"),
+				withPriority(routes[1], "-1"),
+				routes[2],
+			}
+
+			route, err := controllers.SelectGatewayRoute(prioritized, controllers.RouteSelectionPriority, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(route.Name).To(Equal("recent"))
+		})
+
+		It("should fail when more than one route has the highest priority", func() {
+			prioritized := []routev1.Route{
+				withPriority(routes[0], "10"),
+				withPriority(routes[1], "10"),
+				routes[2],
+			}
+
+			_, err := controllers.SelectGatewayRoute(prioritized, controllers.RouteSelectionPriority, "")
+			Expect(err).To(MatchError(ContainSubstring("share the highest priority")))
+		})
+
+		It("should fail when more than one route matches and unique route is expected", func() {
+			_, err := controllers.SelectGatewayRoute(routes, controllers.RouteSelectionUnique, "")
+			Expect(err).To(BeAssignableToTypeOf(&controllers.RouteSelectionError{}))
+		})
+
+		It("should fail for unknown strategy", func() {
+			_, err := controllers.SelectGatewayRoute(routes, "random", "")
+			Expect(err).To(HaveOccurred())
+		})
+
+	})
+
+	When("Mapping ServiceMeshMember events", func() {
+
+		It("should enqueue parent namespace of managed member", func() {
+			// given
+			member := &maistrav1.ServiceMeshMember{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "default",
+					Namespace: "meshified-ns",
+					Labels: map[string]string{
+						controllers.LabelManagedBy: controllers.ManagedByValue,
+					},
+				},
+			}
+
+			// when
+			requests := controllers.ServiceMeshMemberToNamespace(context.Background(), member)
+
+			// then
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].NamespacedName).To(Equal(types.NamespacedName{Name: "meshified-ns"}))
+		})
+
+		It("should ignore members created by someone else", func() {
+			// given
+			member := &maistrav1.ServiceMeshMember{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "default",
+					Namespace: "hand-crafted-ns",
+				},
+			}
+
+			// when
+			requests := controllers.ServiceMeshMemberToNamespace(context.Background(), member)
+
+			// then
+			Expect(requests).To(BeEmpty())
+		})
+
+	})
+
+	When("Summarizing service mesh member conditions", func() {
+
+		memberWith := func(conditions ...maistrav1.ServiceMeshMemberCondition) *maistrav1.ServiceMeshMember {
+			return &maistrav1.ServiceMeshMember{
+				Status: maistrav1.ServiceMeshMemberStatus{Conditions: conditions},
+			}
+		}
+
+		condition := func(conditionType maistrav1.ServiceMeshMemberConditionType, status corev1.ConditionStatus, reason, message string) maistrav1.ServiceMeshMemberCondition {
+			return maistrav1.ServiceMeshMemberCondition{Type: conditionType, Status: status, Reason: reason, Message: message}
+		}
+
+		DescribeTable("it should determine member state",
+			func(member *maistrav1.ServiceMeshMember, expectedState controllers.MemberState, expectedSummary string) {
+				state, summary := controllers.SummarizeMemberConditions(member)
+				Expect(state).To(Equal(expectedState))
+				Expect(summary).To(Equal(expectedSummary))
+			},
+			Entry("pending when mesh has not reported any conditions",
+				memberWith(),
+				controllers.MemberPending, "Pending"),
+			Entry("pending when member is reconciled but not ready",
+				memberWith(
+					condition(maistrav1.ConditionTypeMemberReconciled, corev1.ConditionTrue, "", ""),
+					condition(maistrav1.ConditionTypeMemberReady, corev1.ConditionFalse, "MemberNotReady", ""),
+				),
+				controllers.MemberPending, "Pending: Reconciled=True, Ready=False (MemberNotReady)"),
+			Entry("ready when mesh reports member ready",
+				memberWith(
+					condition(maistrav1.ConditionTypeMemberReconciled, corev1.ConditionTrue, "", ""),
+					condition(maistrav1.ConditionTypeMemberReady, corev1.ConditionTrue, "", ""),
+				),
+				controllers.MemberReady, "Ready: Reconciled=True, Ready=True"),
+			Entry("rejected when mesh fails to reconcile member",
+				memberWith(
+					condition(maistrav1.ConditionTypeMemberReconciled, corev1.ConditionFalse, "ErrorReconciling", "control plane denied membership"),
+					condition(maistrav1.ConditionTypeMemberReady, corev1.ConditionTrue, "", ""),
+				),
+				controllers.MemberRejected, "Rejected: Reconciled=False (ErrorReconciling: control plane denied membership), Ready=True"),
+		)
+
+	})
+
 })
@@ -2,14 +2,24 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/opendatahub-io/odh-project-controller/controllers"
 	"github.com/opendatahub-io/odh-project-controller/version"
+	routev1 "github.com/openshift/api/route/v1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/server"
 )
 
@@ -20,6 +30,14 @@ var (
 	metricsAddr          string
 	enableLeaderElection bool
 	probeAddr            string
+	meshConfigNamespace  string
+	reservedNsInclude    []string
+	reservedNsExclude    []string
+	reservedNsLabel      string
+	disabledFeatures     []string
+	dryRun               bool
+	enableWebhooks       bool
+	meshReadiness        bool
 )
 
 func init() { //nolint:gochecknoinits //reason this way we ensure schemes are always registered before we start anything
@@ -27,11 +45,40 @@ func init() { //nolint:gochecknoinits //reason this way we ensure schemes are al
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "render" {
+		os.Exit(render(os.Args[2:]))
+	}
+
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&meshConfigNamespace, "mesh-config-namespace", os.Getenv("POD_NAMESPACE"),
+		"The namespace of the "+controllers.MeshConfigMapName+" ConfigMap holding mesh configuration. "+
+			"Changes to this ConfigMap are applied without restarting the controller.")
+	reservedNamespaceFlags(flag.CommandLine, &reservedNsInclude, &reservedNsExclude, &reservedNsLabel)
+
+	flag.Func("disable-feature",
+		"Name of the feature which is switched off for all namespaces, resources it created are cleaned up. Can be repeated. "+
+			"Built-in features are "+controllers.FeatureGatewayAnnotations+", "+controllers.FeatureMesh+" and "+controllers.FeatureTemplates+".",
+		func(name string) error {
+			disabledFeatures = append(disabledFeatures, name)
+
+			return nil
+		})
+
+	flag.BoolVar(&dryRun, "dry-run", false,
+		"Work out changes to ServiceMeshMembers and gateway annotations without applying them. "+
+			"Changes are logged and reported as events and metrics instead.")
+
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Serve admission webhooks validating mesh annotations of namespaces and setting gateway annotations of new ones. "+
+			"Serving certificate is expected in the default controller-runtime location.")
+
+	flag.BoolVar(&meshReadiness, "mesh-readiness", true,
+		"Report the controller as ready only when mesh and route APIs are served and the configured ServiceMeshControlPlane exists. "+
+			"When disabled, e.g. to run before OpenShift Service Mesh is installed, these checks are only exported as metrics.")
 
 	opts := zap.Options{
 		Development: true,
@@ -41,8 +88,28 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	meshConfig := controllers.NewMeshConfigFromEnv()
+	if err := meshConfig.Validate(); err != nil {
+		setupLog.Error(err, "invalid mesh configuration")
+		os.Exit(1)
+	}
+
+	reservedNamespaces, err := newReservedNamespaces(reservedNsInclude, reservedNsExclude, reservedNsLabel)
+	if err != nil {
+		setupLog.Error(err, "unable to configure reserved namespaces")
+		os.Exit(1)
+	}
+
+	cacheOpts := cache.Options{}
+	if meshConfigNamespace != "" {
+		cacheOpts.ByObject = map[client.Object]cache.ByObject{
+			&v1.ConfigMap{}: {Namespaces: map[string]cache.Config{meshConfigNamespace: {}}},
+		}
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cacheOpts,
 		Metrics:                server.Options{BindAddress: metricsAddr},
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
@@ -53,27 +120,95 @@ func main() {
 		os.Exit(1)
 	}
 
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+
+	// APIs which could not be discovered are treated as missing and looked up again once the manager starts
+	capabilities, err := controllers.DiscoverCapabilities(discoveryClient)
+	if err != nil {
+		setupLog.Error(err, "unable to discover all APIs served by the cluster, retrying in the background")
+	}
+
+	if missing := capabilities.Missing(); len(missing) > 0 {
+		setupLog.Info("APIs required by some of the features are not served, features will be enabled once the APIs are installed",
+			"missing", missing)
+	}
+
 	ctrlLog := ctrl.Log.WithName("controllers").
 		WithName("odh-project")
 	ctrlLog.Info("creating controller instance", "version", version.Version, "commit", version.Commit, "build-time", version.BuildTime)
 
-	if err = (&controllers.OpenshiftServiceMeshReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrlLog,
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	meshConfigStore := controllers.NewMeshConfigStore(meshConfig, types.NamespacedName{
+		Namespace: meshConfigNamespace,
+		Name:      controllers.MeshConfigMapName,
+	})
+
+	reconciler := &controllers.OpenshiftServiceMeshReconciler{
+		Client:       mgr.GetClient(),
+		Log:          ctrlLog,
+		Scheme:       mgr.GetScheme(),
+		Config:       meshConfigStore,
+		Reserved:     reservedNamespaces,
+		Capabilities: capabilities,
+		Features:     controllers.NewFeatureRegistry(),
+		DryRun:       dryRun,
+	}
+
+	if err := reconciler.Features.Register(reconciler.BuiltinFeatures()...); err != nil {
+		setupLog.Error(err, "unable to register features")
+		os.Exit(1)
+	}
+
+	reconciler.Features.Disable(disabledFeatures...)
+
+	if dryRun {
+		setupLog.Info("Running in dry-run mode, changes are only reported")
+	}
+
+	if err = reconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "odh-project")
 		os.Exit(1)
 	}
 
+	if enableWebhooks {
+		if err := reconciler.SetupWebhooksWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhooks", "webhook", "namespace")
+			os.Exit(1)
+		}
+
+		if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+			setupLog.Error(err, "unable to set up ready check", "check", "webhook")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
 	}
 
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
-		os.Exit(1)
+	readinessChecks := controllers.NewReadinessChecks(discoveryClient, mgr.GetAPIReader(), mgr.GetCache(), meshConfigStore)
+	checkers := readinessChecks.Checkers()
+
+	if !meshReadiness {
+		for name := range readinessChecks.DependencyCheckers() {
+			delete(checkers, name)
+		}
+
+		if err := mgr.Add(manager.RunnableFunc(readinessChecks.MonitorDependencies)); err != nil {
+			setupLog.Error(err, "unable to set up mesh dependency checks")
+			os.Exit(1)
+		}
+	}
+
+	for name, check := range checkers {
+		if err := mgr.AddReadyzCheck(name, check); err != nil {
+			setupLog.Error(err, "unable to set up ready check", "check", name)
+			os.Exit(1)
+		}
 	}
 
 	setupLog.Info("Starting manager")
@@ -83,3 +218,154 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// render prints the ServiceMeshMembers and gateway annotations the controller would create for namespaces
+// read from the given files, without reaching the cluster.
+func render(args []string) int {
+	var (
+		routeFiles    []string
+		include       []string
+		exclude       []string
+		reservedLabel string
+	)
+
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	configFile := flags.String("config", "",
+		"File with the "+controllers.MeshConfigMapName+" ConfigMap. Mesh configuration is read from environment variables when not set.")
+	flags.Func("route",
+		"File with Routes exposing Istio ingress gateway. Gateway annotations are rendered only when provided. Can be repeated.",
+		func(file string) error {
+			routeFiles = append(routeFiles, file)
+
+			return nil
+		})
+	reservedNamespaceFlags(flags, &include, &exclude, &reservedLabel)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s render [flags] namespace.yaml...\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+
+		return 2
+	}
+
+	meshConfig := controllers.NewMeshConfigFromEnv()
+	if *configFile != "" {
+		manifest, err := loadManifest(*configFile)
+		if err != nil {
+			return renderFailed(err)
+		}
+
+		configMap := &v1.ConfigMap{}
+		if err := manifest.Into(configMap, controllers.ByName(controllers.MeshConfigMapName)); err != nil {
+			return renderFailed(errors.Wrapf(err, "failed reading %s", *configFile))
+		}
+
+		if meshConfig, err = controllers.MeshConfigFromConfigMap(configMap, meshConfig); err != nil {
+			return renderFailed(err)
+		}
+	} else if err := meshConfig.Validate(); err != nil {
+		return renderFailed(err)
+	}
+
+	reserved, err := newReservedNamespaces(include, exclude, reservedLabel)
+	if err != nil {
+		return renderFailed(err)
+	}
+
+	reserved.MeshNamespace = func() string {
+		return meshConfig.MeshNamespace
+	}
+
+	var routes []routev1.Route
+
+	for _, file := range routeFiles {
+		manifest, err := loadManifest(file)
+		if err != nil {
+			return renderFailed(err)
+		}
+
+		for _, object := range manifest.Filter(controllers.ByGVK(routev1.GroupVersion.WithKind("Route"))) {
+			routes = append(routes, *object.(*routev1.Route)) //nolint:forcetypeassert //reason: selected by kind
+		}
+	}
+
+	var rendered []controllers.RenderedNamespace
+
+	for _, file := range flags.Args() {
+		manifest, err := loadManifest(file)
+		if err != nil {
+			return renderFailed(err)
+		}
+
+		for _, object := range manifest.Filter(controllers.ByGVK(v1.SchemeGroupVersion.WithKind("Namespace"))) {
+			namespace := object.(*v1.Namespace) //nolint:forcetypeassert //reason: selected by kind
+
+			result, err := controllers.Render(namespace, meshConfig, reserved, routes)
+			if err != nil {
+				return renderFailed(errors.Wrapf(err, "namespace %s", namespace.Name))
+			}
+
+			rendered = append(rendered, result)
+		}
+	}
+
+	if err := controllers.WriteRendered(os.Stdout, rendered); err != nil {
+		return renderFailed(err)
+	}
+
+	return 0
+}
+
+// reservedNamespaceFlags registers flags configuring reserved namespaces, shared by the controller and the render command.
+func reservedNamespaceFlags(flags *flag.FlagSet, include, exclude *[]string, label *string) {
+	flags.Func("reserved-namespaces-include",
+		"Pattern of namespace names which are never enrolled in the mesh. Can be repeated. "+
+			"Defaults to "+strings.Join(controllers.DefaultReservedNamespacePatterns(), ", ")+".",
+		func(pattern string) error {
+			*include = append(*include, pattern)
+
+			return nil
+		})
+	flags.Func("reserved-namespaces-exclude",
+		"Pattern of namespace names which are not reserved, even if matching included patterns. Can be repeated.",
+		func(pattern string) error {
+			*exclude = append(*exclude, pattern)
+
+			return nil
+		})
+	flags.StringVar(label, "reserved-namespace-label", controllers.LabelReservedNamespace,
+		"The label which marks namespace as reserved when set to true. "+
+			"The namespace of the configured mesh is always reserved.")
+}
+
+func newReservedNamespaces(include, exclude []string, label string) (*controllers.ReservedNamespaces, error) {
+	if len(include) == 0 {
+		include = controllers.DefaultReservedNamespacePatterns()
+	}
+
+	reserved, err := controllers.NewReservedNamespaces(include, exclude, label)
+
+	return reserved, errors.Wrap(err, "invalid reserved namespaces configuration")
+}
+
+func loadManifest(file string) (*controllers.Manifest, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed reading %s", file)
+	}
+
+	manifest, err := controllers.LoadManifest(scheme, content)
+
+	return manifest, errors.Wrapf(err, "failed parsing %s", file)
+}
+
+func renderFailed(err error) int {
+	fmt.Fprintln(os.Stderr, "render:", err)
+
+	return 1
+}